@@ -0,0 +1,106 @@
+package posh
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExtractAliases splits a template's aliases from the rest of it. Each
+// template path maps to the deprecated stub path it moved from:
+//
+//	aliases:
+//	  properties.cc.srv_api_uri: properties.cc.api_uri
+func ExtractAliases(root Node) (map[string]string, Node) {
+	aliases := map[string]string{}
+
+	val, rest := extract(root, "aliases")
+
+	declared, ok := val.(map[string]Node)
+	if !ok {
+		return aliases, root
+	}
+
+	for path, oldPath := range declared {
+		str, ok := oldPath.(string)
+		if ok {
+			aliases[path] = str
+		}
+	}
+
+	return aliases, rest
+}
+
+// alias copies each stub's value at a deprecated path to the path it moved
+// to, unless the stub already sets the new path, returning a deprecation
+// warning for each one copied.
+func alias(stubs []Node, aliases map[string]string) ([]Node, []string) {
+	paths := []string{}
+	for path := range aliases {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	aliased := []Node{}
+	warnings := []string{}
+
+	for _, stub := range stubs {
+		for _, path := range paths {
+			newPath := strings.Split(path, ".")
+			oldPath := strings.Split(aliases[path], ".")
+
+			val := findInPath(oldPath, stub)
+			if val == nil || findInPath(newPath, stub) != nil {
+				continue
+			}
+
+			warnings = append(warnings, fmt.Sprintf("%s is deprecated; use %s instead", aliases[path], path))
+
+			stub = setInPath(newPath, stub, val)
+		}
+
+		aliased = append(aliased, stub)
+	}
+
+	return aliased, warnings
+}
+
+// setInPath returns a copy of root with the value at path replaced, adding
+// maps and named list entries as needed
+func setInPath(path []string, root Node, val Node) Node {
+	if len(path) == 0 {
+		return val
+	}
+
+	step := path[0]
+
+	list, ok := root.([]Node)
+	if ok {
+		newList := append([]Node{}, list...)
+
+		for i, entry := range newList {
+			name, ok := keyOf(entry, "name")
+			if ok && name == step {
+				newList[i] = setInPath(path[1:], entry, val)
+				return Node(newList)
+			}
+		}
+
+		entry := map[string]Node{"name": step}
+
+		return Node(append(newList, setInPath(path[1:], entry, val)))
+	}
+
+	attrs, _ := root.(map[string]Node)
+
+	newMap := make(map[string]Node)
+
+	for key, val := range attrs {
+		newMap[key] = val
+	}
+
+	newMap[step] = setInPath(path[1:], attrs[step], val)
+
+	return Node(newMap)
+}
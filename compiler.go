@@ -2,11 +2,45 @@ package posh
 
 import (
 	"container/list"
-	"log"
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 )
 
+// ParseError is an expression that could not be parsed. Line and Column are
+// where parsing stopped, within the expression's source; Position is where
+// that is in the template, once located.
+type ParseError struct {
+	Path     []string
+	Source   string
+	Line     int
+	Column   int
+	Position Position
+
+	// where the expression's source starts in its scalar
+	offset int
+}
+
+func (e *ParseError) Error() string {
+	if e.Position.File != "" {
+		return fmt.Sprintf(
+			"%s: %s: could not parse (( %s ))",
+			e.Position,
+			strings.Join(e.Path, "."),
+			e.Source,
+		)
+	}
+
+	return fmt.Sprintf(
+		"%s: could not parse (( %s )) at line %d, column %d",
+		strings.Join(e.Path, "."),
+		e.Source,
+		e.Line,
+		e.Column,
+	)
+}
+
 type ExprStack struct {
 	list.List
 }
@@ -39,10 +73,14 @@ func (s *ExprStack) PopSeq() *SeqExpr {
 	return seq
 }
 
-func compileTokens(posh *Posh, path []string, context Context) Node {
+func compileTokens(posh *Posh, path []string, context Context) (Node, error) {
 	exprStack := &ExprStack{}
 
 	afterComma := false
+	variables := []string{}
+	strategy := []string{}
+	mergePath := []string{}
+	importKind := ""
 	for token := range posh.Tokens() {
 		contents := posh.Buffer[token.begin:token.end]
 
@@ -53,19 +91,67 @@ func compileTokens(posh *Posh, path []string, context Context) Node {
 			return &PoshNode{
 				Expression: expr,
 
+				source:  strings.TrimSuffix(posh.Buffer, string(END_SYMBOL)),
 				path:    path,
 				context: context,
-			}
+			}, nil
+		case RuleConditional:
+			exprStack.Push(&IfExpr{exprStack.Pop()})
+		case RuleLoop:
+			list := exprStack.Pop()
+
+			variable := variables[len(variables)-1]
+			variables = variables[:len(variables)-1]
+
+			exprStack.Push(&ForExpr{Variable: variable, List: list})
 		case RuleAuto:
 			exprStack.Push(&AutoExpr{path})
 		case RuleMerge:
-			exprStack.Push(&MergeExpr{path})
+			merge := &MergeExpr{Path: path}
+
+			// a merge under a << key merges into the enclosing map
+			if len(path) > 0 && path[len(path)-1] == "<<" {
+				merge.Path = path[:len(path)-1]
+				merge.Deep = true
+			}
+
+			if len(strategy) > 0 {
+				switch strategy[0] {
+				case "append":
+					merge.Strategy = MergeAppend
+				case "prepend":
+					merge.Strategy = MergePrepend
+				case "replace":
+					merge.Strategy = MergeReplace
+				case "on":
+					merge.Key = strategy[1]
+				}
+
+				strategy = nil
+			}
+
+			if len(mergePath) > 0 {
+				merge.Path = mergePath
+				mergePath = nil
+			}
+
+			exprStack.Push(merge)
+		case RuleStrategy:
+			strategy = strings.Fields(contents)
+		case RuleMergePath:
+			mergePath = strings.Split(contents, ".")
+		case RuleImportKind:
+			importKind = contents
+		case RuleImport:
+			file := exprStack.Pop().(*StringExpr)
+
+			exprStack.Push(&ImportExpr{Path: file.Value, Text: importKind == "import_text", At: path})
 		case RuleReference:
 			exprStack.Push(&ReferenceExpr{strings.Split(contents, ".")})
 		case RuleInteger:
 			val, err := strconv.Atoi(contents)
 			if err != nil {
-				panic(err)
+				return nil, err
 			}
 
 			exprStack.Push(&IntegerExpr{val})
@@ -99,7 +185,7 @@ func compileTokens(posh *Posh, path []string, context Context) Node {
 
 			function, ok := exprStack.Pop().(*FunctionExpr)
 			if !ok {
-				panic("non-function in call")
+				return nil, errors.New("non-function in call")
 			}
 
 			exprStack.Push(&CallExpr{
@@ -108,15 +194,41 @@ func compileTokens(posh *Posh, path []string, context Context) Node {
 			})
 		case RuleName:
 			exprStack.Push(&FunctionExpr{Name: contents})
-		case RuleList:
+		case RuleRange:
+			to := exprStack.Pop()
+			from := exprStack.Pop()
+
+			exprStack.Push(&RangeExpr{From: from, To: to})
+		case RuleVariable:
+			variables = append(variables, contents)
+		case RuleComprehension:
+			list := exprStack.Pop()
+			body := exprStack.Pop()
+
+			variable := variables[len(variables)-1]
+			variables = variables[:len(variables)-1]
+
+			exprStack.Push(&ComprehensionExpr{
+				Body:     body,
+				Variable: variable,
+				List:     list,
+			})
+		case RuleContents:
 			seq := exprStack.PopSeq()
 			exprStack.Push(&ListExpr{seq.Expressions})
+		case RuleSpread:
+			seq := exprStack.PopSeq()
+			last := len(seq.Expressions) - 1
+			seq.Expressions[last] = &SpreadExpr{seq.Expressions[last]}
+			exprStack.Push(seq)
+		case RuleEmpty:
+			exprStack.Push(&ListExpr{})
 		case RuleComma:
 			afterComma = true
 		case RuleArguments:
 			// no-op (wrapped by Call)
-		case RuleContents:
-			// no-op (wrapped by List)
+		case RuleList:
+			// no-op (wraps Contents, Range, Comprehension, or Empty)
 		case RuleGrouped:
 			// no-op
 		case RuleLevel0, RuleLevel1, RuleLevel2:
@@ -133,9 +245,28 @@ func compileTokens(posh *Posh, path []string, context Context) Node {
 			}
 		case Rulews:
 		default:
-			log.Fatalln("unhandled:", Rul3s[token.Rule])
+			return nil, errors.New("unhandled: " + Rul3s[token.Rule])
+		}
+	}
+
+	return nil, errors.New("no expression")
+}
+
+// newParseError locates where the parser stopped in an expression
+func newParseError(posh *Posh, path []string) *ParseError {
+	end := 0
+	for _, token := range posh.TokenTree.Error() {
+		if int(token.end) > end {
+			end = int(token.end)
 		}
 	}
 
-	panic("unreachable")
+	position := translatePositions(posh.Buffer, []int{end})[end]
+
+	return &ParseError{
+		Path:   path,
+		Source: strings.TrimSuffix(posh.Buffer, string(END_SYMBOL)),
+		Line:   position.line,
+		Column: position.symbol,
+	}
 }
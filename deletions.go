@@ -0,0 +1,384 @@
+package posh
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// a stub removes a map key with (( delete )) as its value, or a named list
+// entry with a (( delete name )) entry
+var deletion *regexp.Regexp = regexp.MustCompile(`^\(\(\s*delete(?:\s+(\S+))?\s*\)\)$`)
+
+// collectDeletions removes the deletion markers from the stubs, so that
+// they are never merged in, and collects the template paths they mark
+func (s *Spice) collectDeletions() {
+	s.deleting = map[string]bool{}
+
+	stubs := []Node{}
+
+	for _, stub := range s.Stubs {
+		paths := []string{}
+
+		stub = deletions(stub, []string{}, &paths)
+
+		for _, path := range paths {
+			s.deleting[path] = true
+		}
+
+		stubs = append(stubs, stub)
+	}
+
+	s.Stubs = stubs
+}
+
+// deletes checks whether a stub marks a path for deletion, recording the
+// path as deleted if so. Paths are checked as the template takes shape, so
+// loop instances and computed keys can be deleted like any other.
+func (s *Spice) deletes(path []string) bool {
+	if len(s.deleting) == 0 {
+		return false
+	}
+
+	key := strings.Join(path, ".")
+	if !s.deleting[key] {
+		return false
+	}
+
+	for _, gone := range s.Deleted {
+		if gone == key {
+			return true
+		}
+	}
+
+	s.Deleted = append(s.Deleted, key)
+	sort.Strings(s.Deleted)
+
+	return true
+}
+
+// unmatchedDeletions warns about every deletion marker whose path never
+// appeared in the template
+func (s *Spice) unmatchedDeletions() {
+	unmatched := []string{}
+
+	for path := range s.deleting {
+		matched := false
+		for _, gone := range s.Deleted {
+			if gone == path {
+				matched = true
+			}
+		}
+
+		if !matched {
+			unmatched = append(unmatched, path)
+		}
+	}
+
+	sort.Strings(unmatched)
+
+	for _, path := range unmatched {
+		s.Warnings = append(s.Warnings, "a stub deletes "+path+", which the template does not have")
+	}
+}
+
+// deletions returns a copy of the stub without its deletion markers,
+// collecting the paths they mark
+func deletions(stub Node, path []string, paths *[]string) Node {
+	switch stub.(type) {
+	case map[string]Node:
+		newMap := make(map[string]Node)
+
+		for key, val := range stub.(map[string]Node) {
+			sub, ok := deletionMarker(val)
+			if ok && sub == "" {
+				*paths = append(*paths, strings.Join(childPath(path, key), "."))
+				continue
+			}
+
+			newMap[key] = deletions(val, childPath(path, key), paths)
+		}
+
+		return Node(newMap)
+
+	case []Node:
+		newList := []Node{}
+
+		for i, val := range stub.([]Node) {
+			name, ok := deletionMarker(val)
+			if ok && name != "" {
+				*paths = append(*paths, strings.Join(childPath(path, name), "."))
+				continue
+			}
+
+			newList = append(newList, deletions(val, entryPath(path, i, val), paths))
+		}
+
+		return Node(newList)
+	}
+
+	return stub
+}
+
+func deletionMarker(node Node) (string, bool) {
+	str, ok := node.(string)
+	if !ok {
+		return "", false
+	}
+
+	sub := deletion.FindStringSubmatch(str)
+	if sub == nil {
+		return "", false
+	}
+
+	return sub[1], true
+}
+
+// withoutDeleted returns a copy of a settled template without the keys
+// that the stubs deleted
+func withoutDeleted(root Node) Node {
+	switch root.(type) {
+	case map[string]Node:
+		newMap := make(map[string]Node)
+
+		for key, val := range root.(map[string]Node) {
+			_, deleted := val.(*DeletedNode)
+			if !deleted {
+				newMap[key] = withoutDeleted(val)
+			}
+		}
+
+		return Node(newMap)
+
+	case []Node:
+		newList := []Node{}
+
+		for _, val := range root.([]Node) {
+			newList = append(newList, withoutDeleted(val))
+		}
+
+		return Node(newList)
+	}
+
+	return root
+}
+
+// deleteInPath returns a copy of root without the map key or named list
+// entry at path
+func deleteInPath(path []string, root Node) Node {
+	step := path[0]
+
+	switch root.(type) {
+	case map[string]Node:
+		newMap := make(map[string]Node)
+
+		for key, val := range root.(map[string]Node) {
+			switch {
+			case key != step:
+				newMap[key] = val
+			case len(path) > 1:
+				newMap[key] = deleteInPath(path[1:], val)
+			}
+		}
+
+		return Node(newMap)
+
+	case []Node:
+		newList := []Node{}
+
+		for _, entry := range root.([]Node) {
+			name, ok := keyOf(entry, "name")
+
+			switch {
+			case !ok || name != step:
+				newList = append(newList, entry)
+			case len(path) > 1:
+				newList = append(newList, deleteInPath(path[1:], entry))
+			}
+		}
+
+		return Node(newList)
+	}
+
+	return root
+}
+
+// CheckDeleted reports the first unresolved expression that refers to a
+// path deleted by a stub. A reference stops at a deleted key as it resolves,
+// rather than finding a key of the same name further out, so it is left
+// unresolved unless an || falls back. References are followed through the
+// scopes of the expression, so that a name is checked in every scope that
+// could have defined it.
+func CheckDeleted(root Node, deleted []string, entries Entries) error {
+	pending := []*pendingNode{}
+
+	// the empty scope stands in for the environment, as in CheckResolved
+	collectDeleted(root, []string{}, Context{{}}, [][]string{nil}, entries, &pending)
+
+	sort.Sort(byPath(pending))
+
+	for _, node := range pending {
+		for _, ref := range references(node.posh.Expression) {
+			path, gone := node.deletedReference(ref.Path, deleted)
+			if gone {
+				return errors.New(fmt.Sprintf(
+					"%s refers to %s, which was deleted by a stub\n",
+					strings.Join(node.path, "."),
+					path,
+				))
+			}
+		}
+	}
+
+	return nil
+}
+
+// collectDeleted collects every unresolved expression like collectPending,
+// along with the conditions, loops and computed keys that were never
+// decided
+func collectDeleted(root Node, path []string, context Context, scopes [][]string, entries Entries, pending *[]*pendingNode) {
+	undecided := func(expr Expression, source string, body Node) {
+		*pending = append(*pending, &pendingNode{
+			posh:    &PoshNode{Expression: expr, source: source},
+			path:    path,
+			key:     strings.Join(path, "."),
+			context: context,
+			scopes:  scopes,
+		})
+
+		collectDeleted(body, path, context, scopes, entries, pending)
+	}
+
+	switch root.(type) {
+	case map[string]Node:
+		attrs := root.(map[string]Node)
+
+		context = append(append(Context{}, context...), attrs)
+		scopes = append(append([][]string{}, scopes...), path)
+
+		for key, val := range attrs {
+			collectDeleted(val, childPath(path, key), context, scopes, entries, pending)
+		}
+
+	case []Node:
+		list := root.([]Node)
+
+		for i, val := range list {
+			collectDeleted(val, entries.path(path, i, val, len(list)), context, scopes, entries, pending)
+		}
+
+	case *PoshNode:
+		*pending = append(*pending, &pendingNode{
+			posh:    root.(*PoshNode),
+			path:    path,
+			key:     strings.Join(path, "."),
+			context: context,
+			scopes:  scopes,
+		})
+
+	case *ConditionalNode:
+		cond := root.(*ConditionalNode)
+		undecided(cond.Condition, cond.source, cond.Node)
+
+	case *LoopNode:
+		loop := root.(*LoopNode)
+		undecided(loop.List, loop.source, loop.Node)
+
+	case *KeyedNode:
+		keyed := root.(*KeyedNode)
+		undecided(keyed.Key, keyed.source, keyed.Node)
+	}
+}
+
+// deletedReference finds the deleted path that a reference would have
+// resolved to, checking each scope up to the one that now defines it
+func (node *pendingNode) deletedReference(ref []string, deleted []string) (string, bool) {
+	for i := len(node.context) - 1; i >= 0; i-- {
+		if node.scopes[i] != nil {
+			path := strings.Join(append(append([]string{}, node.scopes[i]...), ref...), ".")
+
+			for _, gone := range deleted {
+				if path == gone || strings.HasPrefix(path, gone+".") {
+					return path, true
+				}
+			}
+		}
+
+		if node.context[i][ref[0]] != nil {
+			break
+		}
+	}
+
+	return "", false
+}
+
+// references collects every reference made by an expression
+func references(expr Expression) []*ReferenceExpr {
+	switch expr.(type) {
+	case *ReferenceExpr:
+		return []*ReferenceExpr{expr.(*ReferenceExpr)}
+
+	case *AutoExpr:
+		// sizes are computed from the jobs
+		return []*ReferenceExpr{{Path: []string{"jobs"}}}
+
+	case *OrExpr:
+		e := expr.(*OrExpr)
+		return append(references(e.A), references(e.B)...)
+
+	case *ConcatenationExpr:
+		e := expr.(*ConcatenationExpr)
+		return append(references(e.A), references(e.B)...)
+
+	case *AdditionExpr:
+		e := expr.(*AdditionExpr)
+		return append(references(e.A), references(e.B)...)
+
+	case *SubtractionExpr:
+		e := expr.(*SubtractionExpr)
+		return append(references(e.A), references(e.B)...)
+
+	case *RangeExpr:
+		e := expr.(*RangeExpr)
+		return append(references(e.From), references(e.To)...)
+
+	case *ComprehensionExpr:
+		e := expr.(*ComprehensionExpr)
+		return append(references(e.Body), references(e.List)...)
+
+	case *SeqExpr:
+		return referencesIn(expr.(*SeqExpr).Expressions)
+
+	case *ListExpr:
+		return referencesIn(expr.(*ListExpr).Contents)
+
+	case *CallExpr:
+		return referencesIn(expr.(*CallExpr).Arguments)
+
+	case *SpreadExpr:
+		return references(expr.(*SpreadExpr).List)
+
+	case *IfExpr:
+		return references(expr.(*IfExpr).Condition)
+
+	case *ForExpr:
+		return references(expr.(*ForExpr).List)
+
+	case *BoundExpr:
+		return references(expr.(*BoundExpr).Expression)
+	}
+
+	return nil
+}
+
+func referencesIn(exprs []Expression) []*ReferenceExpr {
+	refs := []*ReferenceExpr{}
+
+	for _, expr := range exprs {
+		refs = append(refs, references(expr)...)
+	}
+
+	return refs
+}
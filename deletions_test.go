@@ -0,0 +1,86 @@
+package posh
+
+import (
+	"testing"
+)
+
+func TestDeleteLoopInstance(t *testing.T) {
+	template := `
+azs: [z1, z2]
+jobs:
+- (( for az in azs )):
+    name: (( "router_" az ))
+`
+
+	stub := `
+jobs:
+- (( delete router_z1 ))
+- (( delete ghost ))
+`
+
+	spice := &Spice{Stubs: []Node{parse(t, stub)}}
+
+	evaluated, err := spice.Evaluate(parse(t, template))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if findInPath([]string{"jobs", "router_z1"}, evaluated) != nil {
+		t.Error("jobs.router_z1 was not deleted")
+	}
+
+	if findInPath([]string{"jobs", "router_z2"}, evaluated) == nil {
+		t.Error("jobs.router_z2 was deleted")
+	}
+
+	if len(spice.Deleted) != 1 || spice.Deleted[0] != "jobs.router_z1" {
+		t.Errorf("expected jobs.router_z1 to be deleted, got %v", spice.Deleted)
+	}
+
+	if len(spice.Warnings) != 1 {
+		t.Errorf("expected a warning about jobs.ghost, got %v", spice.Warnings)
+	}
+}
+
+func TestCheckDeletedNearestScope(t *testing.T) {
+	template := `
+collector:
+  key: outer
+properties:
+  collector:
+    key: secret
+  value: (( collector.key ))
+  fallback: (( collector.key || "none" ))
+`
+
+	stub := `
+properties:
+  collector: (( delete ))
+`
+
+	spice := &Spice{Stubs: []Node{parse(t, stub)}}
+
+	evaluated, err := spice.Evaluate(parse(t, template))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	val := unwrap(findInPath([]string{"properties", "value"}, evaluated))
+	if val != nil {
+		t.Errorf("expected the reference to stop at the deleted key, got %#v", val)
+	}
+
+	val = unwrap(findInPath([]string{"properties", "fallback"}, evaluated))
+	if val != "none" {
+		t.Errorf("expected the reference to the deleted key to fall back, got %#v", val)
+	}
+
+	if findInPath([]string{"properties", "collector"}, evaluated) != nil {
+		t.Error("properties.collector was not deleted")
+	}
+
+	err = CheckDeleted(evaluated, spice.Deleted, spice.Entries)
+	if err == nil {
+		t.Error("expected a reference to a deleted path to be an error")
+	}
+}
@@ -0,0 +1,134 @@
+package posh
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Entries is the identity of each entry of a list, keyed by the list's
+// dotted path. An entry is identified by its index in the template, so
+// that its path stays the same as the entries before it are dropped by
+// conditionals or joined by the instances of a loop. A loop's instances are
+// numbered after the loop, e.g. jobs.2_0, and the entries that a merge
+// takes from the stubs are numbered after the template's.
+//
+// Named entries are addressed by their name, whatever their identity.
+type Entries map[string][]string
+
+// of returns the identities of the entries of a list at a path, or their
+// indices if the list is not the one recorded there
+func (entries Entries) of(path []string, length int) []string {
+	ids, found := entries[strings.Join(path, ".")]
+	if found && len(ids) == length {
+		return ids
+	}
+
+	ids = []string{}
+	for i := 0; i < length; i++ {
+		ids = append(ids, strconv.Itoa(i))
+	}
+
+	return ids
+}
+
+// path addresses the entry of a list at a path by its name, if it has one,
+// and by its identity otherwise
+func (entries Entries) path(path []string, index int, entry Node, length int) []string {
+	return identifiedPath(path, entries.of(path, length)[index], entry)
+}
+
+// find follows a path like findInPath, but steps into unnamed list entries
+// by their identity
+func (entries Entries) find(path []string, root Node) Node {
+	here := root
+
+	for i, step := range path {
+		if here == nil {
+			return nil
+		}
+
+		var found bool
+
+		here, found = entries.step(path[:i], step, here)
+		if !found {
+			return nil
+		}
+	}
+
+	return here
+}
+
+// step takes a step along a path like nextStep, from the value at a path
+func (entries Entries) step(at []string, step string, here Node) (Node, bool) {
+	next, found := nextStep(step, here)
+	if found {
+		return next, true
+	}
+
+	list, ok := unwrap(here).([]Node)
+	if !ok {
+		return nil, false
+	}
+
+	for i, id := range entries.of(at, len(list)) {
+		if id == step {
+			return list[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// forget drops the records of the lists at and under a path, which has been
+// replaced
+func (entries Entries) forget(path []string) {
+	prefix := strings.Join(path, ".")
+
+	for key := range entries {
+		if key == prefix || prefix == "" || strings.HasPrefix(key, prefix+".") {
+			delete(entries, key)
+		}
+	}
+}
+
+// instances identifies the instances of a loop by the loop's identity
+func instances(loop string, count int) []string {
+	ids := []string{}
+	for i := 0; i < count; i++ {
+		ids = append(ids, loop+"_"+strconv.Itoa(i))
+	}
+
+	return ids
+}
+
+// mergedEntries identifies the entries of a list merged with the stubs'
+// lists. The template's entries keep their identities, and any taken from
+// the stubs are numbered from next on, after all of the template's.
+func mergedEntries(merge *MergeExpr, ids []string, count int, next int, stubs []Node) []string {
+	kept := len(ids)
+	for _, stub := range stubs {
+		_, ok := listFrom(findInPath(merge.Path, stub))
+		if ok && merge.Strategy == MergeReplace {
+			kept = 0
+		}
+	}
+
+	// prepended entries come before the template's
+	first := 0
+	if merge.Strategy == MergePrepend {
+		first = count - kept
+	}
+
+	merged := []string{}
+	for i := 0; i < count; i++ {
+		if i >= first && i < first+kept {
+			merged = append(merged, ids[i-first])
+			continue
+		}
+
+		merged = append(merged, strconv.Itoa(next))
+		next++
+	}
+
+	return merged
+}
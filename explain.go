@@ -0,0 +1,543 @@
+package posh
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Derivation explains how a value came about: the expression that produced
+// it, and how each part of that expression was evaluated in turn.
+type Derivation struct {
+	// the template or stub path of the value, if it has one
+	Path []string
+
+	// the expression, or part of one, that produced the value; empty for a
+	// literal of the template
+	Expression string
+
+	// nil if the expression did not evaluate
+	Value Node
+
+	// how the value was found, e.g. "merged from stub 1"
+	Note string
+
+	// the stub (counting from 1) that a merge took the value from, or 0 for
+	// the template
+	Stub int
+
+	Position Position
+
+	Parts []*Derivation
+
+	expr Expression
+}
+
+// String renders the derivation as an indented tree.
+func (d *Derivation) String() string {
+	return d.render("")
+}
+
+func (d *Derivation) render(indent string) string {
+	line := indent
+
+	if d.Position.File != "" {
+		line += d.Position.String() + ": "
+	}
+
+	if d.Expression != "" {
+		line += "(( " + d.Expression + " ))"
+	} else {
+		line += strings.Join(d.Path, ".")
+	}
+
+	if d.Value == nil {
+		line += " is unresolved"
+	} else {
+		line += " = " + show(d.Value)
+	}
+
+	if d.Note != "" {
+		line += " (" + d.Note + ")"
+	}
+
+	line += "\n"
+
+	for _, part := range d.Parts {
+		line += part.render(indent + "  ")
+	}
+
+	return line
+}
+
+// Locate fills in the position of every step of the derivation, looking up
+// template paths in the template's positions and merged paths in the
+// positions of the stub they came from.
+func (d *Derivation) Locate(template Positions, stubs []Positions) {
+	positions := template
+	if d.Stub > 0 && d.Stub <= len(stubs) {
+		positions = stubs[d.Stub-1]
+	}
+
+	if positions != nil && d.Path != nil {
+		pos, found := positions.Find(d.Path)
+		if found {
+			d.Position = pos
+		}
+	}
+
+	for _, part := range d.Parts {
+		part.Locate(template, stubs)
+	}
+}
+
+// Explain traces how the value at a path of an evaluated template was
+// derived, following every reference and merge back to a literal of the
+// template or a stub.
+func (s *Spice) Explain(root Node, path []string) (*Derivation, error) {
+	if s.Entries.find(path, root) == nil {
+		return nil, errors.New(fmt.Sprintf("%s is not defined", strings.Join(path, ".")))
+	}
+
+	explainer := &explainer{spice: s, root: root, explaining: map[string]bool{}}
+
+	return explainer.path(path), nil
+}
+
+// derive records the expression that produced the value at a path. A
+// reference can copy another expression into the path, which resolves again
+// on a later pass; only the first is the path's own.
+func (s *Spice) derive(path []string, posh *PoshNode) {
+	if s.derived == nil {
+		s.derived = map[string]*PoshNode{}
+	}
+
+	key := strings.Join(path, ".")
+
+	_, known := s.derived[key]
+	if !known {
+		s.derived[key] = posh
+	}
+}
+
+type explainer struct {
+	spice *Spice
+	root  Node
+
+	// the paths being explained, so that a cycle is only followed once
+	explaining map[string]bool
+}
+
+// path explains the value at a path of the template
+func (x *explainer) path(path []string) *Derivation {
+	key := strings.Join(path, ".")
+
+	val := x.spice.Entries.find(path, x.root)
+
+	derivation := &Derivation{Path: path, Value: unwrap(val)}
+
+	if x.explaining[key] {
+		derivation.Note = "cycle"
+		return derivation
+	}
+
+	x.explaining[key] = true
+	defer delete(x.explaining, key)
+
+	posh, derived := x.spice.derived[key]
+	if !derived {
+		posh, derived = val.(*PoshNode)
+	}
+
+	if !derived {
+		// the value may be part of one produced by an expression further up
+		for i := len(path) - 1; i > 0; i-- {
+			_, produced := x.spice.derived[strings.Join(path[:i], ".")]
+			if produced {
+				derivation.Note = "part of " + strings.Join(path[:i], ".")
+				derivation.Parts = []*Derivation{x.path(path[:i])}
+				return derivation
+			}
+		}
+
+		// merged lists take their entries from the stubs as they are
+		stub := x.listMerge(path, derivation.Value)
+		if stub > 0 {
+			derivation.Note = fmt.Sprintf("from stub %d", stub)
+			derivation.Stub = stub
+			return derivation
+		}
+
+		derivation.Note = "literal"
+
+		return derivation
+	}
+
+	scopes, err := Scopes(x.root, path, x.spice.Entries)
+	if err != nil {
+		derivation.Note = err.Error()
+		return derivation
+	}
+
+	// the scopes as they were seen while flowing, outermost first
+	chain := []Scope{{Map: x.spice.environment()}}
+	for i := len(scopes) - 1; i >= 0; i-- {
+		chain = append(chain, scopes[i])
+	}
+
+	derivation.Parts = []*Derivation{x.expression(posh.Expression, chain, nil)}
+
+	return derivation
+}
+
+// listMerge finds the stub that a value within a merged list came from, or
+// returns 0 if the value is the template's own
+func (x *explainer) listMerge(path []string, val Node) int {
+	list := 0
+	for i := len(path) - 1; i > 0; i-- {
+		if x.spice.listMerged[strings.Join(path[:i], ".")] {
+			list = i
+			break
+		}
+	}
+
+	if list == 0 {
+		return 0
+	}
+
+	// the last stub defining the value is the one that was merged in
+	for i := len(x.spice.Stubs) - 1; i >= 0; i-- {
+		stubbed := findInPath(path, x.spice.Stubs[i])
+		if stubbed == nil {
+			stubbed = x.unnamedEntry(path, list, x.spice.Stubs[i])
+		}
+
+		if stubbed == nil {
+			continue
+		}
+
+		if show(stubbed) == show(val) {
+			return i + 1
+		}
+
+		break
+	}
+
+	return 0
+}
+
+// unnamedEntry finds a value within an unnamed entry of a merged list in a
+// stub, whose identity in the template says nothing of where it is in the
+// stub's list, by finding an entry just like it
+func (x *explainer) unnamedEntry(path []string, list int, stub Node) Node {
+	entries, ok := listFrom(findInPath(path[:list], stub))
+	if !ok {
+		return nil
+	}
+
+	entry := x.spice.Entries.find(path[:list+1], x.root)
+	if entry == nil {
+		return nil
+	}
+
+	for _, stubbed := range entries {
+		if show(stubbed) == show(unwrap(entry)) {
+			return findInPath(path[list+1:], stubbed)
+		}
+	}
+
+	return nil
+}
+
+// expression explains a part of an expression, evaluated in a chain of
+// scopes along with any loop bindings
+func (x *explainer) expression(expr Expression, chain []Scope, bindings []Binding) *Derivation {
+	bound, ok := expr.(*BoundExpr)
+	if ok {
+		return x.expression(bound.Expression, chain, append(append([]Binding{}, bindings...), bound.Bindings...))
+	}
+
+	context := Context{}
+	for _, scope := range chain {
+		context = append(context, scope.Map)
+	}
+
+	val, _ := (&BoundExpr{Bindings: bindings, Expression: expr}).Evaluate(context, x.spice.Stubs)
+
+	derivation := &Derivation{Expression: describe(expr), Value: unwrap(val), expr: expr}
+
+	parts := func(exprs ...Expression) {
+		for _, part := range exprs {
+			derivation.Parts = append(derivation.Parts, x.expression(part, chain, bindings))
+		}
+	}
+
+	switch expr.(type) {
+	case *ReferenceExpr:
+		ref := expr.(*ReferenceExpr)
+
+		binding, scope, found := symbolScope(ref.Path[0], chain, bindings)
+
+		switch {
+		case !found:
+			derivation.Note = ref.Path[0] + " is not defined"
+		case binding != nil:
+			derivation.Note = "loop variable"
+		default:
+			path := append(append([]string{}, scope.Path...), ref.Path...)
+
+			derivation.Note = "from " + strings.Join(path, ".")
+			derivation.Parts = []*Derivation{x.path(path)}
+		}
+
+	case *MergeExpr:
+		merge := expr.(*MergeExpr)
+		name := strings.Join(merge.Path, ".")
+
+		stub := 0
+		for i := len(x.spice.Stubs) - 1; i >= 0; i-- {
+			if findInPath(merge.Path, x.spice.Stubs[i]) != nil {
+				stub = i + 1
+				break
+			}
+		}
+
+		switch {
+		case stub == 0:
+			derivation.Note = "missed: no stub defines " + name
+		case merge.Deep:
+			derivation.Note = "deep-merged from every stub defining " + name
+		default:
+			derivation.Note = fmt.Sprintf("merged from stub %d", stub)
+			derivation.Parts = []*Derivation{{Path: merge.Path, Value: derivation.Value, Note: "stub value", Stub: stub}}
+		}
+
+	case *AutoExpr:
+		derivation.Note = "computed from the jobs"
+
+	case *OrExpr:
+		e := expr.(*OrExpr)
+
+		parts(e.A, e.B)
+
+		if derivation.Parts[0].Value == nil {
+			derivation.Note = "fell back"
+		}
+
+	case *ConcatenationExpr:
+		e := expr.(*ConcatenationExpr)
+		parts(e.A, e.B)
+
+	case *AdditionExpr:
+		e := expr.(*AdditionExpr)
+		parts(e.A, e.B)
+
+	case *SubtractionExpr:
+		e := expr.(*SubtractionExpr)
+		parts(e.A, e.B)
+
+	case *CallExpr:
+		call := expr.(*CallExpr)
+
+		derivation.Note = "function " + call.Name
+		parts(call.Arguments...)
+
+	case *ListExpr:
+		parts(expr.(*ListExpr).Contents...)
+
+	case *SeqExpr:
+		parts(expr.(*SeqExpr).Expressions...)
+
+	case *SpreadExpr:
+		parts(expr.(*SpreadExpr).List)
+
+	case *RangeExpr:
+		e := expr.(*RangeExpr)
+		parts(e.From, e.To)
+
+	case *ComprehensionExpr:
+		e := expr.(*ComprehensionExpr)
+
+		derivation.Note = "for each " + e.Variable
+		parts(e.List)
+
+	case *IfExpr:
+		parts(expr.(*IfExpr).Condition)
+
+	case *ForExpr:
+		parts(expr.(*ForExpr).List)
+	}
+
+	return derivation
+}
+
+// symbolScope finds the scope that a name resolves in, the same way
+// resolveSymbol does: either a loop binding or a map of the template
+func symbolScope(name string, chain []Scope, bindings []Binding) (map[string]Node, Scope, bool) {
+	for depth := len(chain); depth >= 0; depth-- {
+		for i := len(bindings) - 1; i >= 0; i-- {
+			binding := bindings[i]
+
+			if binding.Depth == depth && binding.Values[name] != nil {
+				return binding.Values, Scope{}, true
+			}
+		}
+
+		if depth > 0 && chain[depth-1].Map[name] != nil {
+			return nil, chain[depth-1], true
+		}
+	}
+
+	return nil, Scope{}, false
+}
+
+// describe renders an expression as it would be written in a template
+func describe(expr Expression) string {
+	switch expr.(type) {
+	case *ReferenceExpr:
+		return strings.Join(expr.(*ReferenceExpr).Path, ".")
+
+	case *MergeExpr:
+		merge := expr.(*MergeExpr)
+
+		switch merge.Strategy {
+		case MergeAppend:
+			return "merge append"
+		case MergePrepend:
+			return "merge prepend"
+		case MergeReplace:
+			return "merge replace"
+		}
+
+		if merge.Key != "" {
+			return "merge on " + merge.Key
+		}
+
+		return "merge"
+
+	case *AutoExpr:
+		return "auto"
+
+	case *ImportExpr:
+		imp := expr.(*ImportExpr)
+
+		if imp.Text {
+			return `import_text "` + imp.Path + `"`
+		}
+
+		return `import "` + imp.Path + `"`
+
+	case *BooleanExpr:
+		return strconv.FormatBool(expr.(*BooleanExpr).Value)
+
+	case *IntegerExpr:
+		return strconv.Itoa(expr.(*IntegerExpr).Value)
+
+	case *StringExpr:
+		return `"` + expr.(*StringExpr).Value + `"`
+
+	case *OrExpr:
+		e := expr.(*OrExpr)
+		return describe(e.A) + " || " + describe(e.B)
+
+	case *ConcatenationExpr:
+		e := expr.(*ConcatenationExpr)
+		return describe(e.A) + " " + describe(e.B)
+
+	case *AdditionExpr:
+		e := expr.(*AdditionExpr)
+		return describe(e.A) + " + " + describe(e.B)
+
+	case *SubtractionExpr:
+		e := expr.(*SubtractionExpr)
+		return describe(e.A) + " - " + describe(e.B)
+
+	case *CallExpr:
+		call := expr.(*CallExpr)
+		return call.Name + "(" + describeAll(call.Arguments, ", ") + ")"
+
+	case *ListExpr:
+		return "[" + describeAll(expr.(*ListExpr).Contents, ", ") + "]"
+
+	case *SeqExpr:
+		return describeAll(expr.(*SeqExpr).Expressions, " ")
+
+	case *SpreadExpr:
+		return describe(expr.(*SpreadExpr).List) + "..."
+
+	case *RangeExpr:
+		e := expr.(*RangeExpr)
+		return "[" + describe(e.From) + ".." + describe(e.To) + "]"
+
+	case *ComprehensionExpr:
+		e := expr.(*ComprehensionExpr)
+		return "[" + describe(e.Body) + " for " + e.Variable + " in " + describe(e.List) + "]"
+
+	case *IfExpr:
+		return "if " + describe(expr.(*IfExpr).Condition)
+
+	case *ForExpr:
+		e := expr.(*ForExpr)
+		return "for " + e.Variable + " in " + describe(e.List)
+
+	case *BoundExpr:
+		return describe(expr.(*BoundExpr).Expression)
+	}
+
+	return fmt.Sprintf("%T", expr)
+}
+
+func describeAll(exprs []Expression, sep string) string {
+	described := []string{}
+
+	for _, expr := range exprs {
+		described = append(described, describe(expr))
+	}
+
+	return strings.Join(described, sep)
+}
+
+// show renders a value on one line
+func show(node Node) string {
+	switch node.(type) {
+	case string:
+		return strconv.Quote(node.(string))
+
+	case TextNode:
+		return strconv.Quote(string(node.(TextNode)))
+
+	case []Node:
+		shown := []string{}
+
+		for _, val := range node.([]Node) {
+			shown = append(shown, show(val))
+		}
+
+		return "[" + strings.Join(shown, ", ") + "]"
+
+	case map[string]Node:
+		attrs := node.(map[string]Node)
+
+		keys := []string{}
+		for key := range attrs {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		shown := []string{}
+
+		for _, key := range keys {
+			shown = append(shown, key+": "+show(attrs[key]))
+		}
+
+		return "{" + strings.Join(shown, ", ") + "}"
+
+	case *PoshNode:
+		return show(node.(*PoshNode).Node)
+	}
+
+	return fmt.Sprintf("%v", node)
+}
@@ -0,0 +1,46 @@
+package posh
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplainStubAttribution(t *testing.T) {
+	template := `
+compilation:
+  workers: 6
+jobs:
+- (( merge ))
+`
+
+	stub := `
+compilation:
+  workers: 6
+jobs:
+- name: nats
+  instances: 2
+`
+
+	spice := &Spice{Stubs: []Node{parse(t, stub)}}
+
+	evaluated, err := spice.Evaluate(parse(t, template))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int{
+		"compilation.workers": 0,
+		"jobs.nats.instances": 1,
+	}
+
+	for path, stub := range expected {
+		derivation, err := spice.Explain(evaluated, strings.Split(path, "."))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if derivation.Stub != stub {
+			t.Errorf("%s: expected stub %d, got %d (%s)", path, stub, derivation.Stub, derivation.Note)
+		}
+	}
+}
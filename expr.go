@@ -1,7 +1,35 @@
 package posh
 
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Expression is a (( ... )) expression. Evaluating it returns its value, or
+// nil while it depends on parts of the template that have not resolved yet.
+// An error means that it never will: either it failed, or something it needs
+// is missing, which an || falls back on.
 type Expression interface {
-	Evaluate(context Context, stub Node) Node
+	Evaluate(context Context, stubs []Node) (Node, error)
+}
+
+// missingError is a stub key or reference that is not defined, and never
+// will be. It is not a failure: an || falls back on it, and an expression
+// that is missing something is left unresolved.
+type missingError struct {
+	reason string
+}
+
+func (e *missingError) Error() string {
+	return e.reason
+}
+
+func missing(err error) bool {
+	_, ok := err.(*missingError)
+	return ok
 }
 
 type AutoExpr struct {
@@ -10,12 +38,36 @@ type AutoExpr struct {
 
 type MergeExpr struct {
 	Path []string
+	Deep bool
+
+	// how an (( merge )) entry in a list combines the list with the stubs'
+	Strategy MergeStrategy
+	Key      string
 }
 
+type MergeStrategy int
+
+const (
+	MergeByKey MergeStrategy = iota
+	MergeAppend
+	MergePrepend
+	MergeReplace
+)
+
 type ReferenceExpr struct {
 	Path []string
 }
 
+// ImportExpr loads a YAML file, or a file's contents as text, relative to
+// the file the expression is in
+type ImportExpr struct {
+	Path string
+	Text bool
+
+	// where the file is spliced in
+	At []string
+}
+
 type BooleanExpr struct {
 	Value bool
 }
@@ -28,6 +80,28 @@ type StringExpr struct {
 	Value string
 }
 
+type IfExpr struct {
+	Condition Expression
+}
+
+type ForExpr struct {
+	Variable string
+	List     Expression
+}
+
+type BoundExpr struct {
+	Bindings   []Binding
+	Expression Expression
+}
+
+// Binding is the scope of a loop variable. It sits among the enclosing
+// scopes at Depth, just inside the map that holds the loop, so that keys of
+// the loop body shadow it and it shadows everything further out.
+type Binding struct {
+	Depth  int
+	Values map[string]Node
+}
+
 type OrExpr struct {
 	A Expression
 	B Expression
@@ -60,23 +134,38 @@ type ListExpr struct {
 	Contents []Expression
 }
 
+type SpreadExpr struct {
+	List Expression
+}
+
+type RangeExpr struct {
+	From Expression
+	To   Expression
+}
+
+type ComprehensionExpr struct {
+	Body     Expression
+	Variable string
+	List     Expression
+}
+
 type CallExpr struct {
 	Name      string
 	Arguments []Expression
 }
 
-func (e *AutoExpr) Evaluate(context Context, stub Node) Node {
+func (e *AutoExpr) Evaluate(context Context, stubs []Node) (Node, error) {
 	if len(e.Path) == 3 && e.Path[0] == "resource_pools" && e.Path[2] == "size" {
 		size := 0
 
 		jobs, found := resolveSymbol("jobs", context)
 		if !found {
-			return nil
+			return nil, nil
 		}
 
 		jobsList, ok := jobs.([]Node)
-		if !ok {
-			return nil
+		if !ok || pendingMerge(jobsList) {
+			return nil, nil
 		}
 
 		for _, job := range []Node(jobsList) {
@@ -101,134 +190,559 @@ func (e *AutoExpr) Evaluate(context Context, stub Node) Node {
 
 			instances, ok := attrs["instances"]
 			if !ok {
-				return nil
+				return nil, nil
 			}
 
 			instanceCount, ok := intFrom(instances)
 			if !ok {
-				return nil
+				return nil, nil
 			}
 
 			size += instanceCount
 		}
 
-		return Node(size)
+		return Node(size), nil
 	}
 
-	return nil
+	return nil, nil
+}
+
+func (e *MergeExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	if e.Deep {
+		merged := map[string]Node{}
+
+		for _, stub := range stubs {
+			val, ok := mapFrom(findInPath(e.Path, stub))
+			if ok {
+				merged = mergeMaps(merged, val)
+			}
+		}
+
+		return Node(merged), nil
+	}
+
+	// later stubs take precedence
+	for i := len(stubs) - 1; i >= 0; i-- {
+		val := findInPath(e.Path, stubs[i])
+		if val != nil {
+			return val, nil
+		}
+	}
+
+	return nil, &missingError{"no stub defines " + strings.Join(e.Path, ".")}
 }
 
-func (e *MergeExpr) Evaluate(context Context, stub Node) Node {
-	return findInPath(e.Path, stub)
+// MergeList combines the entries of a list with the stubs' lists at the same
+// path. Entries are matched by their Key field ("name" by default), and
+// matching stub entries are deep-merged into them.
+func (e *MergeExpr) MergeList(entries []Node, stubs []Node) []Node {
+	merged := entries
+
+	for _, stub := range stubs {
+		list, ok := listFrom(findInPath(e.Path, stub))
+		if !ok {
+			continue
+		}
+
+		switch e.Strategy {
+		case MergeAppend:
+			merged = append(append([]Node{}, merged...), list...)
+		case MergePrepend:
+			merged = append(append([]Node{}, list...), merged...)
+		case MergeReplace:
+			merged = list
+		default:
+			merged = mergeByKey(merged, list, e.key(), mergeEntries)
+		}
+	}
+
+	return merged
 }
 
-func (e *ReferenceExpr) Evaluate(context Context, stub Node) Node {
-	root, found := resolveSymbol(e.Path[0], context)
-	if !found {
-		return nil
+func (e *MergeExpr) key() string {
+	if e.Key == "" {
+		return "name"
 	}
 
-	return findInPath(e.Path[1:], root)
+	return e.Key
 }
 
-func (e *BooleanExpr) Evaluate(Context, Node) Node {
-	return Node(e.Value)
+// mergeByKey merges each override into the entry whose key matches it,
+// appending any that match nothing
+func mergeByKey(entries []Node, overrides []Node, key string, merge func(entry, override Node) Node) []Node {
+	merged := append([]Node{}, entries...)
+
+	for _, override := range overrides {
+		name, ok := keyOf(override, key)
+		if ok {
+			i, found := entryIndex(merged, key, name)
+			if found {
+				merged[i] = merge(merged[i], override)
+				continue
+			}
+		}
+
+		merged = append(merged, override)
+	}
+
+	return merged
 }
 
-func (e *IntegerExpr) Evaluate(Context, Node) Node {
-	return Node(e.Value)
+// mergeEntries merges two list entries as maps
+func mergeEntries(entry, override Node) Node {
+	entryMap, _ := mapFrom(entry)
+	overrideMap, _ := mapFrom(override)
+
+	return Node(mergeMaps(entryMap, overrideMap))
 }
 
-func (e *StringExpr) Evaluate(Context, Node) Node {
-	return Node(e.Value)
+// entryIndex finds the first entry of a list whose key has the given value
+func entryIndex(list []Node, key string, name string) (int, bool) {
+	for i, entry := range list {
+		entryName, ok := keyOf(entry, key)
+		if ok && entryName == name {
+			return i, true
+		}
+	}
+
+	return 0, false
 }
 
-func (e *OrExpr) Evaluate(context Context, stub Node) Node {
-	a := e.A.Evaluate(context, stub)
-	if a != nil {
-		return a
+func keyOf(entry Node, key string) (string, bool) {
+	attrs, ok := mapFrom(entry)
+	if !ok {
+		return "", false
 	}
 
-	return e.B.Evaluate(context, stub)
+	return stringFrom(attrs[key])
 }
 
-func (e *ConcatenationExpr) Evaluate(context Context, stub Node) Node {
-	a := e.A.Evaluate(context, stub)
-	b := e.B.Evaluate(context, stub)
+func (e *ReferenceExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	name := strings.Join(e.Path, ".")
+	level := settlement(context)
 
-	astring, ok := stringFrom(a)
-	if !ok {
-		return nil
+	root, found := resolveSymbol(e.Path[0], context)
+	if !found {
+		// a scope that is still taking shape may yet define the name
+		if level < undefinedMissing || (level < unresolvedMissing && takingShape(context)) {
+			return nil, nil
+		}
+
+		return nil, &missingError{name + " is not defined"}
 	}
 
-	bstring, ok := stringFrom(b)
-	if !ok {
-		return nil
+	// the value, not the expression holding it; expressions are resolved in
+	// place, so a reference to one could otherwise end up referring to itself
+	val, pending := lookup(e.Path[1:], root)
+
+	_, deleted := val.(*DeletedNode)
+
+	switch {
+	case deleted:
+		return nil, &missingError{name + " was deleted by a stub"}
+	case pending && level < unresolvedMissing, val == nil && level < undefinedMissing:
+		return nil, nil
+	case pending:
+		return nil, &missingError{name + " never resolved"}
+	case val == nil:
+		return nil, &missingError{name + " is not defined"}
 	}
 
-	return Node(astring + bstring)
+	return val, nil
 }
 
-func (e *AdditionExpr) Evaluate(context Context, stub Node) Node {
-	a := e.A.Evaluate(context, stub)
-	b := e.B.Evaluate(context, stub)
+func (e *ImportExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	val, _ := resolveSymbol(importDirectory, context)
+	dir, _ := stringFrom(val)
 
-	aint, ok := intFrom(a)
+	path := e.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	importing := []string{}
+
+	val, _ = resolveSymbol(importChain, context)
+	chain, _ := val.([]Node)
+	for _, file := range chain {
+		str, ok := stringFrom(file)
+		if ok {
+			importing = append(importing, str)
+		}
+	}
+
+	val, _ = resolveSymbol(importedPositions, context)
+	positions, ok := val.(Positions)
 	if !ok {
-		return nil
+		positions = Positions{}
 	}
 
-	bint, ok := intFrom(b)
+	val, _ = resolveSymbol(importedLayout, context)
+	layout, ok := val.(Layout)
 	if !ok {
-		return nil
+		layout = Layout{}
+	}
+
+	return importFile(filepath.Clean(path), e.Text, e.At, importing, len(context), positions, layout)
+}
+
+func (e *BooleanExpr) Evaluate(Context, []Node) (Node, error) {
+	return Node(e.Value), nil
+}
+
+func (e *IntegerExpr) Evaluate(Context, []Node) (Node, error) {
+	return Node(e.Value), nil
+}
+
+func (e *StringExpr) Evaluate(Context, []Node) (Node, error) {
+	return Node(e.Value), nil
+}
+
+func (e *IfExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	return e.Condition.Evaluate(context, stubs)
+}
+
+func (e *ForExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	return e.List.Evaluate(context, stubs)
+}
+
+func (e *BoundExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	return e.Expression.Evaluate(e.scope(context), stubs)
+}
+
+// scope places each binding among the scopes of the context; bindings at
+// the same depth are ordered outer loop first
+func (e *BoundExpr) scope(context Context) Context {
+	scope := Context{}
+
+	for depth := 0; depth <= len(context); depth++ {
+		for _, binding := range e.Bindings {
+			if binding.Depth == depth {
+				scope = append(scope, binding.Values)
+			}
+		}
+
+		if depth < len(context) {
+			scope = append(scope, context[depth])
+		}
 	}
 
-	return Node(aint + bint)
+	return scope
 }
 
-func (e *SubtractionExpr) Evaluate(context Context, stub Node) Node {
-	a := e.A.Evaluate(context, stub)
-	b := e.B.Evaluate(context, stub)
+// OrExpr falls back on b only once a is missing, and not while a is still
+// pending; if a fails, so does the whole expression
+func (e *OrExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	a, err := e.A.Evaluate(context, stubs)
+	if !missing(err) {
+		return a, err
+	}
+
+	return e.B.Evaluate(context, stubs)
+}
+
+func (e *ConcatenationExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	a, b, err := evaluatePair(e.A, e.B, context, stubs)
+	if err != nil || a == nil || b == nil {
+		return nil, err
+	}
+
+	astring, ok := scalarString(a)
+	if ok {
+		bstring, ok := scalarString(b)
+		if !ok {
+			return nil, mismatch("concatenate", a, b)
+		}
+
+		return Node(astring + bstring), nil
+	}
+
+	combined := combine(a, b)
+	if combined == nil {
+		return nil, mismatch("concatenate", a, b)
+	}
+
+	return combined, nil
+}
+
+func (e *AdditionExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	a, b, err := evaluatePair(e.A, e.B, context, stubs)
+	if err != nil || a == nil || b == nil {
+		return nil, err
+	}
 
 	aint, ok := intFrom(a)
-	if !ok {
-		return nil
+	if ok {
+		bint, ok := intFrom(b)
+		if !ok {
+			return nil, mismatch("add", a, b)
+		}
+
+		return Node(aint + bint), nil
 	}
 
-	bint, ok := intFrom(b)
-	if !ok {
-		return nil
+	combined := combine(a, b)
+	if combined == nil {
+		return nil, mismatch("add", a, b)
+	}
+
+	return combined, nil
+}
+
+func (e *SubtractionExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	a, b, err := evaluatePair(e.A, e.B, context, stubs)
+	if err != nil || a == nil || b == nil {
+		return nil, err
+	}
+
+	aint, aok := intFrom(a)
+	bint, bok := intFrom(b)
+
+	if !aok || !bok {
+		return nil, mismatch("subtract", a, b)
+	}
+
+	return Node(aint - bint), nil
+}
+
+// evaluatePair evaluates both sides of a binary expression
+func evaluatePair(a, b Expression, context Context, stubs []Node) (Node, Node, error) {
+	aval, err := a.Evaluate(context, stubs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bval, err := b.Evaluate(context, stubs)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return Node(aint - bint)
+	return unwrap(aval), unwrap(bval), nil
 }
 
-func (e *SeqExpr) Evaluate(Context, Node) Node {
-	return Node("TODO Seq")
+func mismatch(op string, a, b Node) error {
+	return errors.New(fmt.Sprintf("cannot %s %s and %s", op, typeName(a), typeName(b)))
 }
 
-func (e *FunctionExpr) Evaluate(Context, Node) Node {
-	return Node("TODO Function")
+func (e *SeqExpr) Evaluate(Context, []Node) (Node, error) {
+	return Node("TODO Seq"), nil
 }
 
-func (e *CallExpr) Evaluate(Context, Node) Node {
-	return Node("TODO Call")
+func (e *FunctionExpr) Evaluate(Context, []Node) (Node, error) {
+	return Node("TODO Function"), nil
+}
+
+func (e *CallExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	switch e.Name {
+	case "range":
+		return e.evaluateRange(context, stubs)
+	case "env":
+		return e.evaluateEnv(context, stubs)
+	}
+
+	return Node("TODO Call"), nil
+}
+
+// range(n) is 0 through n-1; range(a, b) is a through b-1
+func (e *CallExpr) evaluateRange(context Context, stubs []Node) (Node, error) {
+	if len(e.Arguments) != 1 && len(e.Arguments) != 2 {
+		return nil, errors.New("range takes one or two arguments")
+	}
+
+	var bounds []int
+
+	for _, arg := range e.Arguments {
+		val, err := arg.Evaluate(context, stubs)
+		if err != nil || val == nil {
+			return nil, err
+		}
+
+		bound, ok := intFrom(val)
+		if !ok {
+			return nil, errors.New("range takes integers, not " + typeName(unwrap(val)))
+		}
+
+		bounds = append(bounds, bound)
+	}
+
+	if len(bounds) == 1 {
+		return Node(intRange(0, bounds[0]-1)), nil
+	}
+
+	return Node(intRange(bounds[0], bounds[1]-1)), nil
+}
+
+// env("NAME") is the environment variable, which is missing if it is unset
+// or not allowed by the spice
+func (e *CallExpr) evaluateEnv(context Context, stubs []Node) (Node, error) {
+	if len(e.Arguments) != 1 {
+		return nil, errors.New("env takes one argument")
+	}
+
+	val, err := e.Arguments[0].Evaluate(context, stubs)
+	if err != nil || val == nil {
+		return nil, err
+	}
+
+	name, ok := stringFrom(val)
+	if !ok {
+		return nil, errors.New("env takes a string, not " + typeName(unwrap(val)))
+	}
+
+	env, found := resolveSymbol(environmentScope, context)
+	if !found {
+		return nil, nil
+	}
+
+	variable := findInPath([]string{name}, env)
+	if variable == nil {
+		return nil, &missingError{"$" + name + " is unset"}
+	}
+
+	return variable, nil
 }
 
-func (e *ListExpr) Evaluate(context Context, stub Node) Node {
-	var nodes []Node
+func (e *ListExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	nodes := []Node{}
 
 	for _, sub := range e.Contents {
-		nodes = append(nodes, sub.Evaluate(context, stub))
+		spread, ok := sub.(*SpreadExpr)
+		if !ok {
+			evaluated, err := sub.Evaluate(context, stubs)
+			if err != nil || evaluated == nil {
+				return nil, err
+			}
+
+			nodes = append(nodes, evaluated)
+			continue
+		}
+
+		val, err := spread.List.Evaluate(context, stubs)
+		if err != nil || val == nil {
+			return nil, err
+		}
+
+		list, ok := listFrom(val)
+		if !ok {
+			return nil, errors.New("only a list can be spread, not " + typeName(unwrap(val)))
+		}
+
+		nodes = append(nodes, list...)
+	}
+
+	return Node(nodes), nil
+}
+
+// outside of a list literal there is nothing to spread into
+func (e *SpreadExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	return e.List.Evaluate(context, stubs)
+}
+
+func (e *RangeExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	fromVal, toVal, err := evaluatePair(e.From, e.To, context, stubs)
+	if err != nil || fromVal == nil || toVal == nil {
+		return nil, err
+	}
+
+	from, fromOk := intFrom(fromVal)
+	to, toOk := intFrom(toVal)
+
+	if !fromOk || !toOk {
+		return nil, errors.New(fmt.Sprintf("a range needs integer bounds, not %s and %s", typeName(fromVal), typeName(toVal)))
+	}
+
+	return Node(intRange(from, to)), nil
+}
+
+func (e *ComprehensionExpr) Evaluate(context Context, stubs []Node) (Node, error) {
+	val, err := e.List.Evaluate(context, stubs)
+	if err != nil || val == nil {
+		return nil, err
+	}
+
+	list, ok := listFrom(val)
+	if !ok {
+		return nil, errors.New("only a list can be looped over, not " + typeName(unwrap(val)))
+	}
+
+	nodes := []Node{}
+
+	for _, val := range list {
+		// the variable is the nearest scope of the body
+		scope := append(append(Context{}, context...), map[string]Node{e.Variable: val})
+
+		evaluated, err := e.Body.Evaluate(scope, stubs)
+		if err != nil || evaluated == nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, evaluated)
+	}
+
+	return Node(nodes), nil
+}
+
+// intRange includes both ends
+func intRange(from, to int) []Node {
+	nodes := []Node{}
+
+	for i := from; i <= to; i++ {
+		nodes = append(nodes, Node(i))
 	}
 
-	return Node(nodes)
+	return nodes
+}
+
+// combine appends lists and deep-merges maps, with b winning
+func combine(a, b Node) Node {
+	if alist, ok := listFrom(a); ok {
+		blist, ok := listFrom(b)
+		if !ok {
+			return nil
+		}
+
+		return Node(append(append([]Node{}, alist...), blist...))
+	}
+
+	if amap, ok := mapFrom(a); ok {
+		bmap, ok := mapFrom(b)
+		if !ok {
+			return nil
+		}
+
+		return Node(mergeMaps(amap, bmap))
+	}
+
+	return nil
+}
+
+func mergeMaps(a, b map[string]Node) map[string]Node {
+	merged := make(map[string]Node)
+
+	for key, val := range a {
+		merged[key] = val
+	}
+
+	for key, val := range b {
+		amap, aok := mapFrom(merged[key])
+		bmap, bok := mapFrom(val)
+
+		if aok && bok {
+			merged[key] = mergeMaps(amap, bmap)
+		} else {
+			merged[key] = val
+		}
+	}
+
+	return merged
 }
 
 func stringFrom(node Node) (string, bool) {
 	switch node.(type) {
 	case string:
 		return node.(string), true
+	case TextNode:
+		return string(node.(TextNode)), true
 	case *PoshNode:
 		return stringFrom(node.(*PoshNode).Node)
 	default:
@@ -236,6 +750,21 @@ func stringFrom(node Node) (string, bool) {
 	}
 }
 
+// scalarString allows integers to be concatenated into strings
+func scalarString(node Node) (string, bool) {
+	str, ok := stringFrom(node)
+	if ok {
+		return str, true
+	}
+
+	num, ok := intFrom(node)
+	if ok {
+		return strconv.Itoa(num), true
+	}
+
+	return "", false
+}
+
 func intFrom(node Node) (int, bool) {
 	switch node.(type) {
 	case int:
@@ -247,6 +776,128 @@ func intFrom(node Node) (int, bool) {
 	}
 }
 
+// unwrap follows evaluated expressions to their values; unresolved ones
+// unwrap to nil
+func unwrap(node Node) Node {
+	for {
+		posh, ok := node.(*PoshNode)
+		if !ok {
+			return node
+		}
+
+		node = posh.Node
+	}
+}
+
+func listFrom(node Node) ([]Node, bool) {
+	switch node.(type) {
+	case []Node:
+		return node.([]Node), true
+	case *PoshNode:
+		return listFrom(node.(*PoshNode).Node)
+	default:
+		return nil, false
+	}
+}
+
+func mapFrom(node Node) (map[string]Node, bool) {
+	switch node.(type) {
+	case map[string]Node:
+		return node.(map[string]Node), true
+	case *PoshNode:
+		return mapFrom(node.(*PoshNode).Node)
+	default:
+		return nil, false
+	}
+}
+
+func uncompiled(node Node) bool {
+	str, ok := node.(string)
+	return ok && embeddedPosh.MatchString(str)
+}
+
+// undecided checks for a conditional, loop or computed key that its map
+// or list has not decided yet, or a map of one that has not been flowed,
+// which has no value until it is
+func undecided(node Node) bool {
+	switch unwrap(node).(type) {
+	case *ConditionalNode, *LoopNode, *KeyedNode:
+		return true
+
+	case map[string]Node:
+		attrs := unwrap(node).(map[string]Node)
+
+		for key := range attrs {
+			return len(attrs) == 1 && uncompiled(key)
+		}
+	}
+
+	return false
+}
+
+// pending checks for a value that is not known yet: an expression that has
+// not been compiled or resolved, or one that is undecided
+func pending(node Node) bool {
+	return uncompiled(node) || undecided(node) || unwrap(node) == nil
+}
+
+// incomplete checks for a map or list that may still gain keys or entries,
+// once its computed keys, << merges, conditionals, loops, (( merge )) entries
+// and entry names are decided
+func incomplete(node Node) bool {
+	switch unwrap(node).(type) {
+	case map[string]Node:
+		for key, val := range unwrap(node).(map[string]Node) {
+			_, keyed := val.(*KeyedNode)
+			if keyed || key == "<<" || uncompiled(key) {
+				return true
+			}
+		}
+
+	case []Node:
+		for _, entry := range unwrap(node).([]Node) {
+			if uncompiled(entry) || undecided(entry) {
+				return true
+			}
+
+			attrs, ok := entry.(map[string]Node)
+			if !ok {
+				continue
+			}
+
+			name, named := attrs["name"]
+			if named && pending(name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// takingShape checks whether any scope may still gain keys
+func takingShape(context Context) bool {
+	for _, scope := range context {
+		if incomplete(scope) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pendingMerge checks for a list whose (( merge )) entry has not been
+// applied yet, which is not safe to look into
+func pendingMerge(list []Node) bool {
+	for _, val := range list {
+		if uncompiled(val) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func findInPath(path []string, root Node) Node {
 	here := root
 
@@ -266,6 +917,38 @@ func findInPath(path []string, root Node) Node {
 	return here
 }
 
+// lookup follows a path from a value like findInPath, but tells a path that
+// is missing from one that is still pending: an expression along it may not
+// have resolved yet, or a map or list along it may still be taking shape. A
+// path through a deleted key stops there, returning the deleted node.
+func lookup(path []string, root Node) (Node, bool) {
+	here := root
+
+	for _, step := range path {
+		_, deleted := here.(*DeletedNode)
+		if deleted {
+			return here, false
+		}
+
+		if pending(here) {
+			return nil, true
+		}
+
+		next, found := nextStep(step, here)
+		if !found {
+			return nil, incomplete(here)
+		}
+
+		here = next
+	}
+
+	if pending(here) {
+		return nil, true
+	}
+
+	return unwrap(here), false
+}
+
 func nextStep(step string, here Node) (Node, bool) {
 	found := false
 	switch here.(type) {
@@ -273,6 +956,10 @@ func nextStep(step string, here Node) (Node, bool) {
 		found = true
 		here = here.(map[string]Node)[step]
 	case []Node:
+		if pendingMerge(here.([]Node)) {
+			break
+		}
+
 		for _, val := range here.([]Node) {
 			switch val.(type) {
 			case map[string]Node:
@@ -299,9 +986,18 @@ func nextStep(step string, here Node) (Node, bool) {
 	return here, true
 }
 
+// settlement is how far the template has settled, as kept in the
+// environment scope
+func settlement(context Context) int {
+	val, _ := resolveSymbol(settledLevel, context)
+	level, _ := intFrom(val)
+	return level
+}
+
+// resolveSymbol finds a name in the nearest scope that defines it
 func resolveSymbol(name string, context Context) (Node, bool) {
-	for _, ctx := range context {
-		val := ctx[name]
+	for i := len(context) - 1; i >= 0; i-- {
+		val := context[i][name]
 		if val != nil {
 			return val, true
 		}
@@ -0,0 +1,67 @@
+package posh
+
+import (
+	"testing"
+)
+
+func TestReferenceToUndecidedConditional(t *testing.T) {
+	template := `
+settings:
+  prod: (( true ))
+prod: (( settings.prod ))
+suffix:
+  (( if prod )): "-prod"
+full: (( "app" suffix ))
+`
+
+	evaluated := evaluate(t, template)
+
+	val := unwrap(findInPath([]string{"full"}, evaluated))
+	if val != "app-prod" {
+		t.Errorf("expected app-prod, got %#v", val)
+	}
+}
+
+func TestDefaultsPlusMerge(t *testing.T) {
+	template := `templates: (( ["dea_next", "dea_logging_agent"] + (merge || []) ))`
+
+	for stub, expected := range map[string]string{
+		"{}":                  `["dea_next", "dea_logging_agent"]`,
+		"templates: [metron]": `["dea_next", "dea_logging_agent", "metron"]`,
+	} {
+		val := unwrap(findInPath([]string{"templates"}, evaluate(t, template, stub)))
+		if show(val) != expected {
+			t.Errorf("with stub %s: expected %s, got %s", stub, expected, show(val))
+		}
+	}
+}
+
+func TestOrWaitsForPendingReferences(t *testing.T) {
+	template := `
+azs: [z1]
+jobs:
+- (( for az in azs )):
+    name: (( "router_" az ))
+    instances: 3
+total: (( jobs.router_z1.instances || 1 ))
+use_cdn: true
+cdn:
+  (( if use_cdn )):
+    uri: real
+uri: (( cdn.uri || "none" ))
+missing: (( nothere.x || "fallback" ))
+`
+
+	evaluated := evaluate(t, template)
+
+	for path, expected := range map[string]Node{
+		"total":   3,
+		"uri":     "real",
+		"missing": "fallback",
+	} {
+		val := unwrap(findInPath([]string{path}, evaluated))
+		if val != expected {
+			t.Errorf("%s: expected %#v, got %#v", path, expected, val)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package posh
+
+// ExtractBase splits the path of the base a template extends from the rest
+// of it:
+//
+//	extends: base.yml
+func ExtractBase(root Node) (string, Node) {
+	val, rest := extract(root, "extends")
+
+	base, ok := val.(string)
+	if !ok {
+		return "", root
+	}
+
+	return base, rest
+}
+
+// Extend deep-merges a template over its base. Maps are merged key by key,
+// and lists of named entries are merged entry by entry, matching entries by
+// name; anything else in the template replaces the base's value.
+//
+// The top-level prune and aliases are combined rather than replaced, so that
+// a template cannot un-prune what its base hides: the paths pruned by either
+// are pruned, and the template's aliases are added to the base's.
+func Extend(base Node, template Node) Node {
+	baseAliases, base := ExtractAliases(base)
+	aliases, template := ExtractAliases(template)
+
+	basePrunes, base := ExtractPrunes(base)
+	prunes, template := ExtractPrunes(template)
+
+	extended := extend(base, template)
+
+	extendedMap, ok := extended.(map[string]Node)
+	if !ok {
+		return extended
+	}
+
+	if len(baseAliases) > 0 || len(aliases) > 0 {
+		combined := map[string]Node{}
+
+		for path, oldPath := range baseAliases {
+			combined[path] = oldPath
+		}
+
+		for path, oldPath := range aliases {
+			combined[path] = oldPath
+		}
+
+		extendedMap["aliases"] = Node(combined)
+	}
+
+	if len(basePrunes) > 0 || len(prunes) > 0 {
+		combined := []Node{}
+		seen := map[string]bool{}
+
+		for _, path := range append(basePrunes, prunes...) {
+			if !seen[path] {
+				seen[path] = true
+				combined = append(combined, path)
+			}
+		}
+
+		extendedMap["prune"] = Node(combined)
+	}
+
+	return extended
+}
+
+func extend(base Node, template Node) Node {
+	baseMap, baseOk := base.(map[string]Node)
+	templateMap, ok := template.(map[string]Node)
+	if ok && baseOk {
+		merged := make(map[string]Node)
+
+		for key, val := range baseMap {
+			merged[key] = val
+		}
+
+		for key, val := range templateMap {
+			existing, found := merged[key]
+			if found {
+				merged[key] = extend(existing, val)
+			} else {
+				merged[key] = val
+			}
+		}
+
+		return Node(merged)
+	}
+
+	baseList, baseOk := base.([]Node)
+	templateList, ok := template.([]Node)
+	if ok && baseOk && allNamed(templateList) {
+		return Node(mergeByKey(baseList, templateList, "name", extend))
+	}
+
+	return template
+}
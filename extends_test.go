@@ -0,0 +1,81 @@
+package posh
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtendMergesNamedEntries(t *testing.T) {
+	base := parse(t, `
+jobs:
+- name: nats
+  instances: 1
+  networks: [{name: default}]
+- name: router
+  instances: 1
+`)
+
+	template := parse(t, `
+jobs:
+- name: router
+  instances: 2
+- name: collector
+  instances: 1
+`)
+
+	extended := Extend(base, template)
+
+	expected := map[string]Node{
+		"jobs.nats.instances":      1,
+		"jobs.router.instances":    2,
+		"jobs.collector.instances": 1,
+	}
+
+	for path, val := range expected {
+		found := findInPath(strings.Split(path, "."), extended)
+		if found != val {
+			t.Errorf("%s: expected %#v, got %#v", path, val, found)
+		}
+	}
+
+	jobs := findInPath([]string{"jobs"}, extended).([]Node)
+	if len(jobs) != 3 {
+		t.Errorf("expected 3 jobs, got %d", len(jobs))
+	}
+}
+
+func TestExtendCombinesPrunesAndAliases(t *testing.T) {
+	base := parse(t, `
+prune: [secrets]
+aliases:
+  properties.new: properties.old
+  properties.moved: properties.before
+secrets:
+  password: hunter2
+`)
+
+	template := parse(t, `
+prune: [helpers]
+aliases:
+  properties.moved: properties.elsewhere
+helpers:
+  zones: [z1]
+`)
+
+	aliases, rest := ExtractAliases(Extend(base, template))
+	prunes, _ := ExtractPrunes(rest)
+
+	if !reflect.DeepEqual(prunes, []string{"secrets", "helpers"}) {
+		t.Errorf("expected both templates' prunes, got %v", prunes)
+	}
+
+	expected := map[string]string{
+		"properties.new":   "properties.old",
+		"properties.moved": "properties.elsewhere",
+	}
+
+	if !reflect.DeepEqual(aliases, expected) {
+		t.Errorf("expected %v, got %v", expected, aliases)
+	}
+}
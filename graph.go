@@ -0,0 +1,289 @@
+package posh
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxPasses bounds the flow passes of Evaluate, so that a template that
+// never settles is an error rather than a hang
+const MaxPasses = 1000
+
+// pendingNode is an unresolved expression, along with the scopes it is
+// evaluated in and the tree path of each scope
+type pendingNode struct {
+	posh    *PoshNode
+	path    []string
+	key     string
+	context Context
+	scopes  [][]string
+}
+
+// dependencyGraph indexes pending expressions by their path, and by the
+// path of every map and list that holds them, so that the expressions a
+// reference depends on are found without comparing it to every other
+type dependencyGraph struct {
+	at    map[string]*pendingNode
+	under map[string][]*pendingNode
+}
+
+// how far a template has settled, which decides when a reference that is not
+// there is missing, for an || to fall back on, rather than pending
+const (
+	// the template is still taking shape, and may yet define it
+	unsettled = iota
+
+	// nothing changed on the last pass, so a reference that is not defined
+	// never will be
+	undefinedMissing
+
+	// nothing changed even then, so a reference that never resolved, such as
+	// one in a cycle, never will
+	unresolvedMissing
+)
+
+// Evaluate flows a template until nothing changes. Between passes, every
+// unresolved expression is evaluated once, in dependency order, so a chain
+// of references resolves in one pass rather than one pass per link. Only
+// conditionals, loops, computed keys and merges, which change the shape of
+// the tree, need more passes.
+//
+// Once nothing changes, references that are still not defined are missing,
+// and any || expressions fall back on them; the template then flows until
+// nothing changes again.
+//
+// An error is returned for a reference cycle, for an expression that fails
+// to evaluate, or if the template does not settle within MaxPasses.
+func (s *Spice) Evaluate(root Node) (Node, error) {
+	s.settled = unsettled
+
+	for pass := 0; pass < MaxPasses; pass++ {
+		flowed, didFlow, err := s.Flow(root)
+		if err != nil {
+			return nil, err
+		}
+
+		if !didFlow && s.settled < unresolvedMissing {
+			s.settled++
+			continue
+		}
+
+		if !didFlow {
+			s.unmatchedDeletions()
+			s.unmatchedOverrides()
+
+			return withoutDeleted(flowed), s.checkCycles()
+		}
+
+		// whatever fell back may change the shape of the template again
+		s.settled = unsettled
+
+		err = s.resolve(flowed)
+		if err != nil {
+			return nil, err
+		}
+
+		root = flowed
+	}
+
+	return root, errors.New(fmt.Sprintf("template did not settle after %d passes\n", MaxPasses))
+}
+
+// resolve evaluates every unresolved expression in the tree in place, after
+// the expressions it refers to, stopping at the first that fails. The
+// dependency graph is built once, and each expression is evaluated once.
+func (s *Spice) resolve(root Node) error {
+	pending := []*pendingNode{}
+	collectPending(root, []string{}, Context{s.environment()}, [][]string{nil}, s.Entries, &pending)
+
+	sort.Sort(byPath(pending))
+
+	graph := newDependencyGraph(pending)
+
+	s.cycles = nil
+
+	visited := map[*pendingNode]bool{}
+
+	// the expressions being visited, by their depth in the search
+	visiting := []*pendingNode{}
+	depth := map[*pendingNode]int{}
+
+	var failed error
+
+	var visit func(node *pendingNode)
+	visit = func(node *pendingNode) {
+		at, onPath := depth[node]
+		if onPath {
+			cycle := append([]*pendingNode{}, visiting[at:]...)
+			s.cycles = append(s.cycles, append(cycle, node))
+			return
+		}
+
+		if visited[node] || failed != nil {
+			return
+		}
+
+		visited[node] = true
+
+		depth[node] = len(visiting)
+		visiting = append(visiting, node)
+
+		for _, dep := range graph.dependencies(node) {
+			visit(dep)
+		}
+
+		visiting = visiting[:len(visiting)-1]
+		delete(depth, node)
+
+		_, _, err := s.flow(node.posh, node.path, node.context)
+		if err != nil {
+			failed = err
+		}
+	}
+
+	for _, node := range pending {
+		visit(node)
+	}
+
+	return failed
+}
+
+// checkCycles reports the first reference cycle whose expressions never
+// resolved, along with the source of each
+func (s *Spice) checkCycles() error {
+	for _, cycle := range s.cycles {
+		unresolved := true
+		for _, node := range cycle {
+			if unwrap(node.posh) != nil {
+				unresolved = false
+			}
+		}
+
+		if !unresolved {
+			continue
+		}
+
+		paths := []string{}
+		sources := ""
+
+		for i, node := range cycle {
+			path := strings.Join(node.path, ".")
+			paths = append(paths, path)
+
+			if i < len(cycle)-1 {
+				sources += fmt.Sprintf("  %s: (( %s ))\n", path, node.posh.source)
+			}
+		}
+
+		return errors.New(fmt.Sprintf(
+			"reference cycle: %s\n%s",
+			strings.Join(paths, " -> "),
+			sources,
+		))
+	}
+
+	return nil
+}
+
+func collectPending(root Node, path []string, context Context, scopes [][]string, entries Entries, pending *[]*pendingNode) {
+	switch root.(type) {
+	case map[string]Node:
+		attrs := root.(map[string]Node)
+
+		// sibling maps must not share the chain's backing array
+		context = append(append(Context{}, context...), attrs)
+		scopes = append(append([][]string{}, scopes...), path)
+
+		for key, val := range attrs {
+			collectPending(val, childPath(path, key), context, scopes, entries, pending)
+		}
+
+	case []Node:
+		list := root.([]Node)
+
+		for i, val := range list {
+			collectPending(val, entries.path(path, i, val, len(list)), context, scopes, entries, pending)
+		}
+
+	case *PoshNode:
+		*pending = append(*pending, &pendingNode{
+			posh:    root.(*PoshNode),
+			path:    path,
+			key:     strings.Join(path, "."),
+			context: context,
+			scopes:  scopes,
+		})
+	}
+}
+
+func newDependencyGraph(pending []*pendingNode) *dependencyGraph {
+	graph := &dependencyGraph{
+		at:    map[string]*pendingNode{},
+		under: map[string][]*pendingNode{},
+	}
+
+	for _, node := range pending {
+		graph.at[node.key] = node
+
+		for i := 0; i < len(node.path); i++ {
+			ancestor := strings.Join(node.path[:i], ".")
+			graph.under[ancestor] = append(graph.under[ancestor], node)
+		}
+	}
+
+	return graph
+}
+
+// dependencies finds the unresolved expressions within or around the paths
+// that an expression refers to
+func (graph *dependencyGraph) dependencies(node *pendingNode) []*pendingNode {
+	deps := []*pendingNode{}
+
+	for _, ref := range references(node.posh.Expression) {
+		path, ok := node.absolute(ref.Path)
+		if !ok {
+			continue
+		}
+
+		// the value holding the path, or the path itself, is unresolved
+		for i := 0; i <= len(path); i++ {
+			holder, found := graph.at[strings.Join(path[:i], ".")]
+			if found {
+				deps = append(deps, holder)
+			}
+		}
+
+		deps = append(deps, graph.under[strings.Join(path, ".")]...)
+	}
+
+	return deps
+}
+
+// absolute finds the tree path of a reference by the scope it resolves in,
+// the same way resolveSymbol does
+func (node *pendingNode) absolute(path []string) ([]string, bool) {
+	for i := len(node.context) - 1; i >= 0; i-- {
+		if node.context[i][path[0]] == nil {
+			continue
+		}
+
+		if node.scopes[i] == nil {
+			return nil, false
+		}
+
+		return append(append([]string{}, node.scopes[i]...), path...), true
+	}
+
+	return nil, false
+}
+
+type byPath []*pendingNode
+
+func (nodes byPath) Len() int      { return len(nodes) }
+func (nodes byPath) Swap(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] }
+
+func (nodes byPath) Less(i, j int) bool {
+	return nodes[i].key < nodes[j].key
+}
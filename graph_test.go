@@ -0,0 +1,86 @@
+package posh
+
+import (
+	"fmt"
+	"testing"
+
+	"launchpad.net/goyaml"
+)
+
+func parse(t *testing.T, source string) Node {
+	var parsed interface{}
+
+	err := goyaml.Unmarshal([]byte(source), &parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node, err := Sanitize(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return node
+}
+
+func evaluate(t *testing.T, template string, stubs ...string) Node {
+	spice := &Spice{}
+
+	for _, stub := range stubs {
+		spice.Stubs = append(spice.Stubs, parse(t, stub))
+	}
+
+	evaluated, err := spice.Evaluate(parse(t, template))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return evaluated
+}
+
+func TestEvaluateFallbackInCycle(t *testing.T) {
+	evaluated := evaluate(t, "a: (( b || 1 ))\nb: (( a ))\n")
+
+	for _, key := range []string{"a", "b"} {
+		val := unwrap(findInPath([]string{key}, evaluated))
+		if val != 1 {
+			t.Errorf("%s: expected 1, got %#v", key, val)
+		}
+	}
+}
+
+func TestEvaluateSiblingScopes(t *testing.T) {
+	// the scope chain of one map must not leak into its siblings'
+	template := `
+a:
+  b: {yv: 1, x: (( yv + 1 ))}
+  c: {yv: str}
+  d: {yv: str2}
+  e: {yv: str3}
+`
+
+	for i := 0; i < 20; i++ {
+		evaluated := evaluate(t, template)
+
+		val := unwrap(findInPath([]string{"a", "b", "x"}, evaluated))
+		if val != 2 {
+			t.Fatalf("expected 2, got %#v", val)
+		}
+	}
+}
+
+func TestEvaluateLongChain(t *testing.T) {
+	template := "chain:\n"
+	for i := 0; i < 2000; i++ {
+		template += fmt.Sprintf("  k%d: (( k%d ))\n", i, i+1)
+	}
+
+	template += "  k2000: 1\n"
+
+	evaluated := evaluate(t, template)
+
+	val := unwrap(findInPath([]string{"chain", "k0"}, evaluated))
+	if val != 1 {
+		t.Errorf("expected 1, got %#v", val)
+	}
+}
@@ -0,0 +1,151 @@
+package posh
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"launchpad.net/goyaml"
+)
+
+// importChain is the files being imported, outermost first, which is kept
+// in the scope of each file's imports to detect cycles
+const importChain = "$importing"
+
+// importedPositions and importedLayout collect where the imported files'
+// contents came from, which are kept in the environment scope
+const importedPositions = "$positions"
+const importedLayout = "$layout"
+
+// Within scopes the imports in a template loaded from source to it, so that
+// they are found relative to the template, wherever it is extended from,
+// and so that a template importing itself is caught.
+func Within(root Node, source string) Node {
+	return within(root, filepath.Dir(source), []string{filepath.Clean(source)}, 1, []string{})
+}
+
+// within binds the directory and the chain of files being imported in every
+// import of a file, at the depth of the scope the file is spliced into
+func within(root Node, dir string, importing []string, depth int, path []string) Node {
+	chain := []Node{}
+	for _, file := range importing {
+		chain = append(chain, Node(file))
+	}
+
+	binding := Binding{
+		Depth: depth,
+		Values: map[string]Node{
+			importDirectory: Node(dir),
+			importChain:     Node(chain),
+		},
+	}
+
+	return bindImports(root, binding, path)
+}
+
+func bindImports(root Node, binding Binding, path []string) Node {
+	switch root.(type) {
+	case map[string]Node:
+		bound := make(map[string]Node)
+
+		for key, val := range root.(map[string]Node) {
+			bound[key] = bindImports(val, binding, childPath(path, key))
+		}
+
+		return Node(bound)
+
+	case []Node:
+		bound := []Node{}
+
+		for i, val := range root.([]Node) {
+			bound = append(bound, bindImports(val, binding, entryPath(path, i, val)))
+		}
+
+		return Node(bound)
+
+	case string:
+		if !imports(root.(string)) {
+			return root
+		}
+
+		posh, ok := compileEmbedded(root.(string), path, nil).(*PoshNode)
+		if !ok {
+			return root
+		}
+
+		posh.Expression = &BoundExpr{Bindings: []Binding{binding}, Expression: posh.Expression}
+
+		return posh
+	}
+
+	return root
+}
+
+// imports checks whether an expression imports a file
+func imports(source string) bool {
+	sub := embeddedPosh.FindStringSubmatch(source)
+	if sub == nil {
+		return false
+	}
+
+	posh := &Posh{Buffer: sub[1]}
+	posh.Init()
+
+	if err := posh.Parse(); err != nil {
+		return false
+	}
+
+	for token := range posh.Tokens() {
+		if token.Rule == RuleImport {
+			return true
+		}
+	}
+
+	return false
+}
+
+// importFile loads a file to splice in at a path, as text or as YAML,
+// scoping the file's own imports to it. The files being imported, outermost
+// first, are given to detect cycles, along with the depth of the scope the
+// file is spliced into.
+func importFile(path string, text bool, at []string, importing []string, depth int, positions Positions, layout Layout) (Node, error) {
+	for _, seen := range importing {
+		if seen == path {
+			return nil, errors.New(fmt.Sprintf(
+				"import cycle: %s -> %s\n",
+				strings.Join(importing, " -> "),
+				path,
+			))
+		}
+	}
+
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if text {
+		return Node(TextNode(source)), nil
+	}
+
+	var parsed interface{}
+
+	err = goyaml.Unmarshal(source, &parsed)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error parsing %s: %s\n", path, err))
+	}
+
+	sanitized, err := Sanitize(parsed)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("error parsing %s: %s\n", path, err))
+	}
+
+	located, laidOut := Locate(source, path)
+
+	positions.Add(at, located)
+	layout.Add(at, laidOut)
+
+	return within(sanitized, filepath.Dir(path), append(append([]string{}, importing...), path), depth, at), nil
+}
@@ -0,0 +1,136 @@
+package posh
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportExpressions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "posh-imports")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"clients.yml": "login:\n  password: (( secret ))\n",
+		"script.sh":   "x=$(( 1 + 2 ))\ny=(( not_an_expr ))\n",
+	}
+
+	for name, contents := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	template := `
+secret: hunter2
+clients: (( import "clients.yml" ))
+script: (( import_text "script.sh" ))
+`
+
+	spice := &Spice{Dir: dir}
+
+	evaluated, err := spice.Evaluate(parse(t, template))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret, _ := stringFrom(findInPath([]string{"clients", "login", "password"}, evaluated))
+	if secret != "hunter2" {
+		t.Errorf("expected the imported expression to resolve, got %#v", secret)
+	}
+
+	script, _ := stringFrom(findInPath([]string{"script"}, evaluated))
+	if script != files["script.sh"] {
+		t.Errorf("expected the text as it is, got %#v", script)
+	}
+}
+
+func TestImportsRelativeToImportingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "posh-imports")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	err = os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"template.yml": "nested: (( import \"sub/a.yml\" ))\n",
+		"sub/a.yml":    "more: (( import \"more.yml\" ))\n",
+		"sub/more.yml": "value: found\n",
+		"more.yml":     "value: wrong\n",
+	}
+
+	for name, contents := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	templatePath := filepath.Join(dir, "template.yml")
+
+	spice := &Spice{}
+
+	evaluated, err := spice.Evaluate(Within(parse(t, files["template.yml"]), templatePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, _ := stringFrom(findInPath([]string{"nested", "more", "value"}, evaluated))
+	if value != "found" {
+		t.Errorf("expected the import relative to sub/a.yml, got %#v", value)
+	}
+
+	_, found := spice.Positions["nested.more.value"]
+	if !found {
+		t.Errorf("expected the imported positions to be recorded, got %v", spice.Positions)
+	}
+}
+
+func TestImportCycle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "posh-imports")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"template.yml": "self: (( import \"template.yml\" ))\n",
+		"a.yml":        "b: (( import \"b.yml\" ))\n",
+		"b.yml":        "a: (( import \"a.yml\" ))\n",
+	}
+
+	for name, contents := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spice := &Spice{}
+
+	_, err = spice.Evaluate(Within(parse(t, files["template.yml"]), filepath.Join(dir, "template.yml")))
+	if err == nil || !strings.Contains(err.Error(), "import cycle") {
+		t.Errorf("expected a self-import to be an import cycle, got %v", err)
+	}
+
+	spice = &Spice{Dir: dir}
+
+	_, err = spice.Evaluate(parse(t, "a: (( import \"a.yml\" ))"))
+	if err == nil || !strings.Contains(err.Error(), "import cycle") {
+		t.Errorf("expected a.yml and b.yml to be an import cycle, got %v", err)
+	}
+}
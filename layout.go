@@ -0,0 +1,405 @@
+package posh
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"launchpad.net/goyaml"
+)
+
+// Layout is the key order and comments of a template, keyed by dotted path
+// like Positions, so unnamed list entries are keyed by their index. Nodes
+// are Go maps, which keep neither, so the manifest is laid out again from
+// it when rendering.
+type Layout map[string]Placement
+
+// Placement is where a key goes among its siblings, along with the comments
+// written above it and after its value.
+type Placement struct {
+	Order int
+
+	HeadComment []string
+	LineComment string
+}
+
+// Add records the layout of another document under a path. Keys already
+// laid out keep their place, and any new ones follow them.
+func (layout Layout) Add(path []string, other Layout) {
+	offset := 0
+	for _, placement := range layout {
+		if placement.Order >= offset {
+			offset = placement.Order + 1
+		}
+	}
+
+	for key, placement := range other {
+		full := strings.Join(path, ".")
+		if key != "" {
+			if full != "" {
+				full += "."
+			}
+
+			full += key
+		}
+
+		_, known := layout[full]
+		if !known {
+			placement.Order += offset
+			layout[full] = placement
+		}
+	}
+}
+
+// Render marshals a manifest with its keys in the order of the template,
+// along with the template's comments. Keys that the template does not have,
+// such as those merged in from stubs, follow the ones it does. Unnamed list
+// entries are laid out by their identity, so each keeps its own comments
+// whatever was dropped or added before it.
+//
+// goyaml sorts keys and drops comments, but its output is otherwise kept
+// as is: its blocks are only moved around.
+func Render(root Node, layout Layout, entries Entries) ([]byte, error) {
+	marshaled, err := goyaml.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(marshaled), "\n"), "\n")
+
+	parsed, next, ok := parseBlock(lines, 0, 0)
+	if !ok || next != len(lines) {
+		// not laid out the way goyaml lays out a document; leave it be
+		return marshaled, nil
+	}
+
+	laidOut := parsed.render(root, []string{}, layout, entries)
+
+	return []byte(strings.Join(laidOut, "\n") + "\n"), nil
+}
+
+// renderedBlock is a value marshaled by goyaml: a map, a list, or a scalar
+// and its continuation lines
+type renderedBlock struct {
+	entries []*renderedEntry
+	items   []*renderedBlock
+	lines   []string
+}
+
+type renderedEntry struct {
+	key string
+
+	// the key, and its value if it is a scalar
+	lines []string
+	value *renderedBlock
+}
+
+// parseBlock parses the value starting at a line, which is indented by
+// indent
+func parseBlock(lines []string, start int, indent int) (*renderedBlock, int, bool) {
+	line := lines[start][indent:]
+
+	if line == "-" || strings.HasPrefix(line, "- ") {
+		return parseList(lines, start, indent)
+	}
+
+	_, _, isKey := renderedKey(line)
+	if isKey {
+		return parseMap(lines, start, indent)
+	}
+
+	// a list item's folded lines are indented further than its marker
+	end := continuation(lines, start+1, indent-2)
+
+	return &renderedBlock{lines: lines[start:end]}, end, true
+}
+
+func parseMap(lines []string, start int, indent int) (*renderedBlock, int, bool) {
+	block := &renderedBlock{}
+
+	i := start
+	for i < len(lines) && indentation(lines[i]) == indent && !isItem(lines[i][indent:]) {
+		key, rest, ok := renderedKey(lines[i][indent:])
+		if !ok {
+			return nil, 0, false
+		}
+
+		entry := &renderedEntry{key: key}
+
+		if rest != "" {
+			end := continuation(lines, i+1, indent)
+
+			entry.lines = lines[i:end]
+			i = end
+		} else {
+			entry.lines = lines[i : i+1]
+			i++
+
+			if i >= len(lines) {
+				return nil, 0, false
+			}
+
+			// goyaml puts a list at the same indentation as its key
+			valueIndent := indentation(lines[i])
+			if valueIndent < indent || (valueIndent == indent && !isItem(lines[i][indent:])) {
+				return nil, 0, false
+			}
+
+			value, next, ok := parseBlock(lines, i, valueIndent)
+			if !ok {
+				return nil, 0, false
+			}
+
+			entry.value = value
+			i = next
+		}
+
+		block.entries = append(block.entries, entry)
+	}
+
+	return block, i, true
+}
+
+func parseList(lines []string, start int, indent int) (*renderedBlock, int, bool) {
+	block := &renderedBlock{}
+
+	i := start
+	for i < len(lines) && indentation(lines[i]) == indent && isItem(lines[i][indent:]) {
+		if lines[i][indent:] == "-" {
+			return nil, 0, false
+		}
+
+		// the item's value, as if it were on a line of its own; the marker is
+		// put back when rendering
+		lines[i] = lines[i][:indent] + "  " + lines[i][indent+2:]
+
+		item, next, ok := parseBlock(lines, i, indent+2)
+		if !ok {
+			return nil, 0, false
+		}
+
+		block.items = append(block.items, item)
+		i = next
+	}
+
+	return block, i, true
+}
+
+// render lays out the block's maps, walking the manifest alongside it to
+// find the path of each entry
+func (block *renderedBlock) render(node Node, path []string, layout Layout, entries Entries) []string {
+	switch {
+	case block.entries != nil:
+		attrs, _ := node.(map[string]Node)
+
+		sorted := append([]*renderedEntry{}, block.entries...)
+
+		sort.Stable(byPlacement{sorted, path, layout})
+
+		lines := []string{}
+
+		for _, entry := range sorted {
+			entryPath := childPath(path, entry.key)
+			placement, placed := layout[strings.Join(entryPath, ".")]
+
+			indent := strings.Repeat(" ", indentation(entry.lines[0]))
+
+			for _, comment := range placement.HeadComment {
+				lines = append(lines, indent+comment)
+			}
+
+			entryLines := append([]string{}, entry.lines...)
+
+			if placed && placement.LineComment != "" {
+				at := 0
+				if entry.value == nil && len(entryLines) > 1 && !isBlockScalar(entryLines[0]) {
+					// after the last line of a folded scalar
+					at = len(entryLines) - 1
+				}
+
+				entryLines[at] += " " + placement.LineComment
+			}
+
+			lines = append(lines, entryLines...)
+
+			if entry.value != nil {
+				lines = append(lines, entry.value.render(attrs[entry.key], entryPath, layout, entries)...)
+			}
+		}
+
+		return lines
+
+	case block.items != nil:
+		list, _ := node.([]Node)
+
+		lines := []string{}
+
+		for i, item := range block.items {
+			var val Node
+			if i < len(list) {
+				val = list[i]
+			}
+
+			itemPath := entryPath(path, i, val)
+			if len(block.items) == len(list) {
+				itemPath = entries.path(path, i, val, len(list))
+			}
+
+			itemLines := item.render(val, itemPath, layout, entries)
+
+			// the first line of the item is marked, below any of its comments
+			marked := 0
+			for marked < len(itemLines)-1 && isComment(itemLines[marked]) {
+				marked++
+			}
+
+			indent := indentation(itemLines[marked]) - 2
+
+			for _, comment := range layout[strings.Join(itemPath, ".")].HeadComment {
+				lines = append(lines, strings.Repeat(" ", indent)+comment)
+			}
+
+			for j, line := range itemLines {
+				switch {
+				case j < marked:
+					lines = append(lines, strings.Repeat(" ", indent)+strings.TrimLeft(line, " "))
+				case j == marked:
+					lines = append(lines, line[:indent]+"- "+line[indent+2:])
+				default:
+					lines = append(lines, line)
+				}
+			}
+		}
+
+		return lines
+	}
+
+	return block.lines
+}
+
+// renderedKey splits a line marshaled by goyaml into its key and the rest
+// of the line, if it has a key
+func renderedKey(line string) (string, string, bool) {
+	if line == "" {
+		return "", "", false
+	}
+
+	switch line[0] {
+	case '"':
+		end := 1
+		for end < len(line) && line[end] != '"' {
+			if line[end] == '\\' {
+				end++
+			}
+
+			end++
+		}
+
+		if end >= len(line) || !strings.HasPrefix(line[end+1:], ":") {
+			return "", "", false
+		}
+
+		key, err := strconv.Unquote(line[:end+1])
+		if err != nil {
+			return "", "", false
+		}
+
+		return key, strings.TrimPrefix(line[end+2:], " "), true
+
+	case '\'':
+		end := 1
+		for end < len(line) {
+			if line[end] == '\'' {
+				if end+1 < len(line) && line[end+1] == '\'' {
+					end += 2
+					continue
+				}
+
+				break
+			}
+
+			end++
+		}
+
+		if end >= len(line) || !strings.HasPrefix(line[end+1:], ":") {
+			return "", "", false
+		}
+
+		key := strings.Replace(line[1:end], "''", "'", -1)
+
+		return key, strings.TrimPrefix(line[end+2:], " "), true
+	}
+
+	colon := strings.Index(line, ": ")
+	if colon < 0 {
+		if !strings.HasSuffix(line, ":") {
+			return "", "", false
+		}
+
+		colon = len(line) - 1
+	}
+
+	return line[:colon], strings.TrimPrefix(line[colon+1:], " "), true
+}
+
+// continuation finds the end of a scalar, whose folded lines are indented
+// further than it. A block scalar may have empty lines within it.
+func continuation(lines []string, start int, indent int) int {
+	end := start
+	for i := start; i < len(lines); i++ {
+		if lines[i] == "" {
+			continue
+		}
+
+		if indentation(lines[i]) <= indent {
+			break
+		}
+
+		end = i + 1
+	}
+
+	return end
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func isItem(line string) bool {
+	return line == "-" || strings.HasPrefix(line, "- ")
+}
+
+func isComment(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " "), "#")
+}
+
+func isBlockScalar(line string) bool {
+	_, rest, ok := renderedKey(strings.TrimLeft(line, " "))
+	return ok && (strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, ">"))
+}
+
+// byPlacement orders entries by the template, followed by those it doesn't
+// have
+type byPlacement struct {
+	entries []*renderedEntry
+	path    []string
+	layout  Layout
+}
+
+func (entries byPlacement) Len() int { return len(entries.entries) }
+
+func (entries byPlacement) Swap(i, j int) {
+	entries.entries[i], entries.entries[j] = entries.entries[j], entries.entries[i]
+}
+
+func (entries byPlacement) Less(i, j int) bool {
+	a, aPlaced := entries.layout[strings.Join(childPath(entries.path, entries.entries[i].key), ".")]
+	b, bPlaced := entries.layout[strings.Join(childPath(entries.path, entries.entries[j].key), ".")]
+
+	switch {
+	case aPlaced && bPlaced:
+		return a.Order < b.Order
+	default:
+		return aPlaced && !bPlaced
+	}
+}
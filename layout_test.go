@@ -0,0 +1,131 @@
+package posh
+
+import (
+	"testing"
+)
+
+func render(t *testing.T, template string) string {
+	_, layout := Locate([]byte(template), "layout.yml")
+
+	spice := &Spice{}
+
+	evaluated, err := spice.Evaluate(parse(t, template))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err := Render(evaluated, layout, spice.Entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(rendered)
+}
+
+func TestRenderKeepsOrderAndComments(t *testing.T) {
+	template := `# the deployment
+name: (( "dep" "loyment" ))
+# every job
+jobs:
+- name: router # routes
+  instances: 2
+- name: nats
+  instances: 1
+compilation:
+  workers: 6
+`
+
+	rendered := render(t, template)
+
+	expected := `# the deployment
+name: deployment
+# every job
+jobs:
+- name: router # routes
+  instances: 2
+- name: nats
+  instances: 1
+compilation:
+  workers: 6
+`
+
+	if rendered != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, rendered)
+	}
+}
+
+func TestRenderUnnamedEntries(t *testing.T) {
+	// each entry keeps its own comments, though they share their keys
+	template := `things:
+# first thing
+- b: 1 # bee one
+  a: 2
+# second thing
+- b: 3
+  a: 4
+`
+
+	rendered := render(t, template)
+
+	if rendered != template {
+		t.Errorf("expected:\n%s\ngot:\n%s", template, rendered)
+	}
+}
+
+func TestRenderFlowCollections(t *testing.T) {
+	template := `inline: {z: 1, m: {d: 1, c: 2}}
+flows: [{name: one, z: 1, m: 2}, {z: 3,
+  m: 4}]
+`
+
+	rendered := render(t, template)
+
+	expected := `inline:
+  z: 1
+  m:
+    d: 1
+    c: 2
+flows:
+- name: one
+  z: 1
+  m: 2
+- z: 3
+  m: 4
+`
+
+	if rendered != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, rendered)
+	}
+}
+
+func TestRenderUnnamedEntriesAfterConditionals(t *testing.T) {
+	// the entries keep their comments when one before them is dropped
+	template := `flag: false
+things:
+# first thing
+- (( if flag )):
+    b: 1
+# second thing
+- b: 2 # bee two
+  a: 3
+# third thing
+- b: 4
+  a: 5
+`
+
+	rendered := render(t, template)
+
+	expected := `flag: false
+things:
+# second thing
+- b: 2 # bee two
+  a: 3
+# third thing
+- b: 4
+  a: 5
+`
+
+	if rendered != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, rendered)
+	}
+}
@@ -0,0 +1,113 @@
+package posh
+
+import (
+	"sort"
+	"strings"
+)
+
+// collectOverrides finds the paths that the overrides set. Maps and named
+// list entries are descended into; any other value replaces the template's
+// entirely. Later overrides take precedence.
+func (s *Spice) collectOverrides() {
+	s.overriding = map[string]Node{}
+
+	for _, override := range s.Overrides {
+		overrides(override, []string{}, s.overriding)
+	}
+}
+
+func overrides(override Node, path []string, paths map[string]Node) {
+	switch override.(type) {
+	case map[string]Node:
+		for key, val := range override.(map[string]Node) {
+			overrides(val, childPath(path, key), paths)
+		}
+
+		return
+
+	case []Node:
+		list := override.([]Node)
+		if len(list) == 0 || !allNamed(list) {
+			break
+		}
+
+		for _, entry := range list {
+			name, _ := keyOf(entry, "name")
+
+			for key, val := range entry.(map[string]Node) {
+				// the name only matches the entry; it doesn't change it
+				if key != "name" {
+					overrides(val, childPath(childPath(path, name), key), paths)
+				}
+			}
+		}
+
+		return
+	}
+
+	paths[strings.Join(path, ".")] = override
+}
+
+// override replaces the value at a path with an override's, recording the
+// path as overridden. Paths are checked as the template takes shape, so loop
+// instances, computed keys and the bodies of conditionals can be overridden
+// like any other; each is only replaced the first time it appears.
+func (s *Spice) override(path []string, val Node) (Node, bool) {
+	if len(s.overriding) == 0 {
+		return val, false
+	}
+
+	key := strings.Join(path, ".")
+
+	replacement, found := s.overriding[key]
+	if !found {
+		return val, false
+	}
+
+	for _, done := range s.Overridden {
+		if done == key {
+			return val, false
+		}
+	}
+
+	s.Overridden = append(s.Overridden, key)
+	sort.Strings(s.Overridden)
+
+	return replacement, true
+}
+
+// unmatchedOverrides warns about every override whose path never appeared
+// in the template
+func (s *Spice) unmatchedOverrides() {
+	unmatched := []string{}
+
+	for path := range s.overriding {
+		matched := false
+		for _, done := range s.Overridden {
+			if done == path {
+				matched = true
+			}
+		}
+
+		if !matched {
+			unmatched = append(unmatched, path)
+		}
+	}
+
+	sort.Strings(unmatched)
+
+	for _, path := range unmatched {
+		s.Warnings = append(s.Warnings, "an override sets "+path+", which the template does not have")
+	}
+}
+
+func allNamed(list []Node) bool {
+	for _, entry := range list {
+		_, ok := keyOf(entry, "name")
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
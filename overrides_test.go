@@ -0,0 +1,84 @@
+package posh
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOverridesAppearingPaths(t *testing.T) {
+	template := `
+azs: [z1, z2]
+jobs:
+- (( for az in azs )):
+    name: (( "router_" az ))
+    instances: 3
+use_cdn: true
+cdn:
+  (( if use_cdn )):
+    uri: real
+port: 80
+total: (( jobs.router_z1.instances ))
+`
+
+	override := `
+jobs:
+- name: router_z1
+  instances: 9
+cdn:
+  uri: overridden
+port: 8080
+ghost: boo
+`
+
+	spice := &Spice{Overrides: []Node{parse(t, override)}}
+
+	evaluated, err := spice.Evaluate(parse(t, template))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, expected := range map[string]Node{
+		"jobs.router_z1.instances": 9,
+		"jobs.router_z2.instances": 3,
+		"cdn.uri":                  "overridden",
+		"port":                     8080,
+		"total":                    9,
+	} {
+		val := unwrap(findInPath(strings.Split(path, "."), evaluated))
+		if val != expected {
+			t.Errorf("%s: expected %#v, got %#v", path, expected, val)
+		}
+	}
+
+	expected := []string{"cdn.uri", "jobs.router_z1.instances", "port"}
+	if !reflect.DeepEqual(spice.Overridden, expected) {
+		t.Errorf("expected %v to be overridden, got %v", expected, spice.Overridden)
+	}
+
+	if len(spice.Warnings) != 1 || !strings.Contains(spice.Warnings[0], "ghost") {
+		t.Errorf("expected a warning about ghost, got %v", spice.Warnings)
+	}
+}
+
+func TestLaterOverridesTakePrecedence(t *testing.T) {
+	template := `
+port: 80
+`
+
+	spice := &Spice{
+		Overrides: []Node{
+			parse(t, "port: 8080"),
+			parse(t, "port: 9090"),
+		},
+	}
+
+	evaluated, err := spice.Evaluate(parse(t, template))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if val := unwrap(findInPath([]string{"port"}, evaluated)); val != 9090 {
+		t.Errorf("expected 9090, got %#v", val)
+	}
+}
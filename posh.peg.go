@@ -16,6 +16,8 @@ type Rule uint8
 const (
 	RuleUnknown Rule = iota
 	RulePosh
+	RuleConditional
+	RuleLoop
 	RuleExpression
 	RuleLevel2
 	RuleOr
@@ -34,8 +36,18 @@ const (
 	RuleBoolean
 	RuleList
 	RuleContents
+	RuleSpread
+	RuleEmpty
+	RuleRange
+	RuleComprehension
+	RuleVariable
+	RuleKeyword
 	RuleMerge
+	RuleMergePath
+	RuleStrategy
 	RuleAuto
+	RuleImport
+	RuleImportKind
 	RuleReference
 	Rulews
 
@@ -47,6 +59,8 @@ const (
 var Rul3s = [...]string{
 	"Unknown",
 	"Posh",
+	"Conditional",
+	"Loop",
 	"Expression",
 	"Level2",
 	"Or",
@@ -65,8 +79,18 @@ var Rul3s = [...]string{
 	"Boolean",
 	"List",
 	"Contents",
+	"Spread",
+	"Empty",
+	"Range",
+	"Comprehension",
+	"Variable",
+	"Keyword",
 	"Merge",
+	"MergePath",
+	"Strategy",
 	"Auto",
+	"Import",
+	"ImportKind",
 	"Reference",
 	"ws",
 
@@ -570,7 +594,7 @@ func (t *tokens32) Expand(index int) TokenTree {
 
 type Posh struct {
 	Buffer string
-	rules  [24]func() bool
+	rules  [36]func() bool
 	Parse  func(rule ...int) error
 	Reset  func()
 	TokenTree
@@ -699,23 +723,39 @@ func (p *Posh) Init() {
 
 	rules = [...]func() bool{
 		nil,
-		/* 0 Posh <- <(Expression !.)> */
+		/* 0 Posh <- <((Conditional / Loop / Expression) !.)> */
 		func() bool {
 			position0, tokenIndex0, depth0 := position, tokenIndex, depth
 			{
 				position1 := position
 				depth++
-				if !rules[RuleExpression]() {
-					goto l0
-				}
 				{
 					position2, tokenIndex2, depth2 := position, tokenIndex, depth
+					if !rules[RuleConditional]() {
+						goto l3
+					}
+					goto l2
+				l3:
+					position, tokenIndex, depth = position2, tokenIndex2, depth2
+					if !rules[RuleLoop]() {
+						goto l4
+					}
+					goto l2
+				l4:
+					position, tokenIndex, depth = position2, tokenIndex2, depth2
+					if !rules[RuleExpression]() {
+						goto l0
+					}
+				}
+			l2:
+				{
+					position5, tokenIndex5, depth5 := position, tokenIndex, depth
 					if !matchDot() {
-						goto l2
+						goto l5
 					}
 					goto l0
-				l2:
-					position, tokenIndex, depth = position2, tokenIndex2, depth2
+				l5:
+					position, tokenIndex, depth = position5, tokenIndex5, depth5
 				}
 				depth--
 				add(RulePosh, position1)
@@ -725,990 +765,2176 @@ func (p *Posh) Init() {
 			position, tokenIndex, depth = position0, tokenIndex0, depth0
 			return false
 		},
-		/* 1 Expression <- <Level2> */
+		/* 1 Conditional <- <(('i' 'f') (' ' / '\t' / '\n' / '\r')+ Expression)> */
+		func() bool {
+			position6, tokenIndex6, depth6 := position, tokenIndex, depth
+			{
+				position7 := position
+				depth++
+				if buffer[position] != 'i' {
+					goto l6
+				}
+				position++
+				if buffer[position] != 'f' {
+					goto l6
+				}
+				position++
+				{
+					position10, tokenIndex10, depth10 := position, tokenIndex, depth
+					if buffer[position] != ' ' {
+						goto l11
+					}
+					position++
+					goto l10
+				l11:
+					position, tokenIndex, depth = position10, tokenIndex10, depth10
+					if buffer[position] != '\t' {
+						goto l12
+					}
+					position++
+					goto l10
+				l12:
+					position, tokenIndex, depth = position10, tokenIndex10, depth10
+					if buffer[position] != '\n' {
+						goto l13
+					}
+					position++
+					goto l10
+				l13:
+					position, tokenIndex, depth = position10, tokenIndex10, depth10
+					if buffer[position] != '\r' {
+						goto l6
+					}
+					position++
+				}
+			l10:
+			l8:
+				{
+					position9, tokenIndex9, depth9 := position, tokenIndex, depth
+					{
+						position14, tokenIndex14, depth14 := position, tokenIndex, depth
+						if buffer[position] != ' ' {
+							goto l15
+						}
+						position++
+						goto l14
+					l15:
+						position, tokenIndex, depth = position14, tokenIndex14, depth14
+						if buffer[position] != '\t' {
+							goto l16
+						}
+						position++
+						goto l14
+					l16:
+						position, tokenIndex, depth = position14, tokenIndex14, depth14
+						if buffer[position] != '\n' {
+							goto l17
+						}
+						position++
+						goto l14
+					l17:
+						position, tokenIndex, depth = position14, tokenIndex14, depth14
+						if buffer[position] != '\r' {
+							goto l9
+						}
+						position++
+					}
+				l14:
+					goto l8
+				l9:
+					position, tokenIndex, depth = position9, tokenIndex9, depth9
+				}
+				if !rules[RuleExpression]() {
+					goto l6
+				}
+				depth--
+				add(RuleConditional, position7)
+			}
+			return true
+		l6:
+			position, tokenIndex, depth = position6, tokenIndex6, depth6
+			return false
+		},
+		/* 2 Loop <- <(('f' 'o' 'r') (' ' / '\t' / '\n' / '\r')+ Variable ws ('i' 'n') ws Expression)> */
+		func() bool {
+			position18, tokenIndex18, depth18 := position, tokenIndex, depth
+			{
+				position19 := position
+				depth++
+				if buffer[position] != 'f' {
+					goto l18
+				}
+				position++
+				if buffer[position] != 'o' {
+					goto l18
+				}
+				position++
+				if buffer[position] != 'r' {
+					goto l18
+				}
+				position++
+				{
+					position22, tokenIndex22, depth22 := position, tokenIndex, depth
+					if buffer[position] != ' ' {
+						goto l23
+					}
+					position++
+					goto l22
+				l23:
+					position, tokenIndex, depth = position22, tokenIndex22, depth22
+					if buffer[position] != '\t' {
+						goto l24
+					}
+					position++
+					goto l22
+				l24:
+					position, tokenIndex, depth = position22, tokenIndex22, depth22
+					if buffer[position] != '\n' {
+						goto l25
+					}
+					position++
+					goto l22
+				l25:
+					position, tokenIndex, depth = position22, tokenIndex22, depth22
+					if buffer[position] != '\r' {
+						goto l18
+					}
+					position++
+				}
+			l22:
+			l20:
+				{
+					position21, tokenIndex21, depth21 := position, tokenIndex, depth
+					{
+						position26, tokenIndex26, depth26 := position, tokenIndex, depth
+						if buffer[position] != ' ' {
+							goto l27
+						}
+						position++
+						goto l26
+					l27:
+						position, tokenIndex, depth = position26, tokenIndex26, depth26
+						if buffer[position] != '\t' {
+							goto l28
+						}
+						position++
+						goto l26
+					l28:
+						position, tokenIndex, depth = position26, tokenIndex26, depth26
+						if buffer[position] != '\n' {
+							goto l29
+						}
+						position++
+						goto l26
+					l29:
+						position, tokenIndex, depth = position26, tokenIndex26, depth26
+						if buffer[position] != '\r' {
+							goto l21
+						}
+						position++
+					}
+				l26:
+					goto l20
+				l21:
+					position, tokenIndex, depth = position21, tokenIndex21, depth21
+				}
+				if !rules[RuleVariable]() {
+					goto l18
+				}
+				if !rules[Rulews]() {
+					goto l18
+				}
+				if buffer[position] != 'i' {
+					goto l18
+				}
+				position++
+				if buffer[position] != 'n' {
+					goto l18
+				}
+				position++
+				if !rules[Rulews]() {
+					goto l18
+				}
+				if !rules[RuleExpression]() {
+					goto l18
+				}
+				depth--
+				add(RuleLoop, position19)
+			}
+			return true
+		l18:
+			position, tokenIndex, depth = position18, tokenIndex18, depth18
+			return false
+		},
+		/* 3 Expression <- <Level2> */
 		func() bool {
-			position3, tokenIndex3, depth3 := position, tokenIndex, depth
+			position30, tokenIndex30, depth30 := position, tokenIndex, depth
 			{
-				position4 := position
+				position31 := position
 				depth++
 				if !rules[RuleLevel2]() {
-					goto l3
+					goto l30
 				}
 				depth--
-				add(RuleExpression, position4)
+				add(RuleExpression, position31)
 			}
 			return true
-		l3:
-			position, tokenIndex, depth = position3, tokenIndex3, depth3
+		l30:
+			position, tokenIndex, depth = position30, tokenIndex30, depth30
 			return false
 		},
-		/* 2 Level2 <- <(Or / Level1)> */
+		/* 4 Level2 <- <(Or / Level1)> */
 		func() bool {
-			position5, tokenIndex5, depth5 := position, tokenIndex, depth
+			position32, tokenIndex32, depth32 := position, tokenIndex, depth
 			{
-				position6 := position
+				position33 := position
 				depth++
 				{
-					position7, tokenIndex7, depth7 := position, tokenIndex, depth
+					position34, tokenIndex34, depth34 := position, tokenIndex, depth
 					if !rules[RuleOr]() {
-						goto l8
+						goto l35
 					}
-					goto l7
-				l8:
-					position, tokenIndex, depth = position7, tokenIndex7, depth7
+					goto l34
+				l35:
+					position, tokenIndex, depth = position34, tokenIndex34, depth34
 					if !rules[RuleLevel1]() {
-						goto l5
+						goto l32
 					}
 				}
-			l7:
+			l34:
 				depth--
-				add(RuleLevel2, position6)
+				add(RuleLevel2, position33)
 			}
 			return true
-		l5:
-			position, tokenIndex, depth = position5, tokenIndex5, depth5
+		l32:
+			position, tokenIndex, depth = position32, tokenIndex32, depth32
 			return false
 		},
-		/* 3 Or <- <(Level1 ws ('|' '|') ws Expression)> */
+		/* 5 Or <- <(Level1 ws ('|' '|') ws Expression)> */
 		func() bool {
-			position9, tokenIndex9, depth9 := position, tokenIndex, depth
+			position36, tokenIndex36, depth36 := position, tokenIndex, depth
 			{
-				position10 := position
+				position37 := position
 				depth++
 				if !rules[RuleLevel1]() {
-					goto l9
+					goto l36
 				}
 				if !rules[Rulews]() {
-					goto l9
+					goto l36
 				}
 				if buffer[position] != '|' {
-					goto l9
+					goto l36
 				}
 				position++
 				if buffer[position] != '|' {
-					goto l9
+					goto l36
 				}
 				position++
 				if !rules[Rulews]() {
-					goto l9
+					goto l36
 				}
 				if !rules[RuleExpression]() {
-					goto l9
+					goto l36
 				}
 				depth--
-				add(RuleOr, position10)
+				add(RuleOr, position37)
 			}
 			return true
-		l9:
-			position, tokenIndex, depth = position9, tokenIndex9, depth9
+		l36:
+			position, tokenIndex, depth = position36, tokenIndex36, depth36
 			return false
 		},
-		/* 4 Level1 <- <(Concatenation / Addition / Subtraction / Level0)> */
+		/* 6 Level1 <- <(Concatenation / Addition / Subtraction / Level0)> */
 		func() bool {
-			position11, tokenIndex11, depth11 := position, tokenIndex, depth
+			position38, tokenIndex38, depth38 := position, tokenIndex, depth
 			{
-				position12 := position
+				position39 := position
 				depth++
 				{
-					position13, tokenIndex13, depth13 := position, tokenIndex, depth
+					position40, tokenIndex40, depth40 := position, tokenIndex, depth
 					if !rules[RuleConcatenation]() {
-						goto l14
+						goto l41
 					}
-					goto l13
-				l14:
-					position, tokenIndex, depth = position13, tokenIndex13, depth13
+					goto l40
+				l41:
+					position, tokenIndex, depth = position40, tokenIndex40, depth40
 					if !rules[RuleAddition]() {
-						goto l15
+						goto l42
 					}
-					goto l13
-				l15:
-					position, tokenIndex, depth = position13, tokenIndex13, depth13
+					goto l40
+				l42:
+					position, tokenIndex, depth = position40, tokenIndex40, depth40
 					if !rules[RuleSubtraction]() {
-						goto l16
+						goto l43
 					}
-					goto l13
-				l16:
-					position, tokenIndex, depth = position13, tokenIndex13, depth13
+					goto l40
+				l43:
+					position, tokenIndex, depth = position40, tokenIndex40, depth40
 					if !rules[RuleLevel0]() {
-						goto l11
+						goto l38
 					}
 				}
-			l13:
+			l40:
 				depth--
-				add(RuleLevel1, position12)
+				add(RuleLevel1, position39)
 			}
 			return true
-		l11:
-			position, tokenIndex, depth = position11, tokenIndex11, depth11
+		l38:
+			position, tokenIndex, depth = position38, tokenIndex38, depth38
 			return false
 		},
-		/* 5 Concatenation <- <(Level0 (' ' / '\t' / '\n' / '\r')+ Level1)> */
+		/* 7 Concatenation <- <(Level0 (' ' / '\t' / '\n' / '\r')+ Level1)> */
 		func() bool {
-			position17, tokenIndex17, depth17 := position, tokenIndex, depth
+			position44, tokenIndex44, depth44 := position, tokenIndex, depth
 			{
-				position18 := position
+				position45 := position
 				depth++
 				if !rules[RuleLevel0]() {
-					goto l17
+					goto l44
 				}
 				{
-					position21, tokenIndex21, depth21 := position, tokenIndex, depth
+					position48, tokenIndex48, depth48 := position, tokenIndex, depth
 					if buffer[position] != ' ' {
-						goto l22
+						goto l49
 					}
 					position++
-					goto l21
-				l22:
-					position, tokenIndex, depth = position21, tokenIndex21, depth21
+					goto l48
+				l49:
+					position, tokenIndex, depth = position48, tokenIndex48, depth48
 					if buffer[position] != '\t' {
-						goto l23
+						goto l50
 					}
 					position++
-					goto l21
-				l23:
-					position, tokenIndex, depth = position21, tokenIndex21, depth21
+					goto l48
+				l50:
+					position, tokenIndex, depth = position48, tokenIndex48, depth48
 					if buffer[position] != '\n' {
-						goto l24
+						goto l51
 					}
 					position++
-					goto l21
-				l24:
-					position, tokenIndex, depth = position21, tokenIndex21, depth21
+					goto l48
+				l51:
+					position, tokenIndex, depth = position48, tokenIndex48, depth48
 					if buffer[position] != '\r' {
-						goto l17
+						goto l44
 					}
 					position++
 				}
-			l21:
-			l19:
+			l48:
+			l46:
 				{
-					position20, tokenIndex20, depth20 := position, tokenIndex, depth
+					position47, tokenIndex47, depth47 := position, tokenIndex, depth
 					{
-						position25, tokenIndex25, depth25 := position, tokenIndex, depth
+						position52, tokenIndex52, depth52 := position, tokenIndex, depth
 						if buffer[position] != ' ' {
-							goto l26
+							goto l53
 						}
 						position++
-						goto l25
-					l26:
-						position, tokenIndex, depth = position25, tokenIndex25, depth25
+						goto l52
+					l53:
+						position, tokenIndex, depth = position52, tokenIndex52, depth52
 						if buffer[position] != '\t' {
-							goto l27
+							goto l54
 						}
 						position++
-						goto l25
-					l27:
-						position, tokenIndex, depth = position25, tokenIndex25, depth25
+						goto l52
+					l54:
+						position, tokenIndex, depth = position52, tokenIndex52, depth52
 						if buffer[position] != '\n' {
-							goto l28
+							goto l55
 						}
 						position++
-						goto l25
-					l28:
-						position, tokenIndex, depth = position25, tokenIndex25, depth25
+						goto l52
+					l55:
+						position, tokenIndex, depth = position52, tokenIndex52, depth52
 						if buffer[position] != '\r' {
-							goto l20
+							goto l47
 						}
 						position++
 					}
-				l25:
-					goto l19
-				l20:
-					position, tokenIndex, depth = position20, tokenIndex20, depth20
+				l52:
+					goto l46
+				l47:
+					position, tokenIndex, depth = position47, tokenIndex47, depth47
 				}
 				if !rules[RuleLevel1]() {
-					goto l17
+					goto l44
 				}
 				depth--
-				add(RuleConcatenation, position18)
+				add(RuleConcatenation, position45)
 			}
 			return true
-		l17:
-			position, tokenIndex, depth = position17, tokenIndex17, depth17
+		l44:
+			position, tokenIndex, depth = position44, tokenIndex44, depth44
 			return false
 		},
-		/* 6 Addition <- <(Level0 ws '+' ws Level1)> */
+		/* 8 Addition <- <(Level0 ws '+' ws Level1)> */
 		func() bool {
-			position29, tokenIndex29, depth29 := position, tokenIndex, depth
+			position56, tokenIndex56, depth56 := position, tokenIndex, depth
 			{
-				position30 := position
+				position57 := position
 				depth++
 				if !rules[RuleLevel0]() {
-					goto l29
+					goto l56
 				}
 				if !rules[Rulews]() {
-					goto l29
+					goto l56
 				}
 				if buffer[position] != '+' {
-					goto l29
+					goto l56
 				}
 				position++
 				if !rules[Rulews]() {
-					goto l29
+					goto l56
 				}
 				if !rules[RuleLevel1]() {
-					goto l29
+					goto l56
 				}
 				depth--
-				add(RuleAddition, position30)
+				add(RuleAddition, position57)
 			}
 			return true
-		l29:
-			position, tokenIndex, depth = position29, tokenIndex29, depth29
+		l56:
+			position, tokenIndex, depth = position56, tokenIndex56, depth56
 			return false
 		},
-		/* 7 Subtraction <- <(Level0 ws '-' ws Level1)> */
+		/* 9 Subtraction <- <(Level0 ws '-' ws Level1)> */
 		func() bool {
-			position31, tokenIndex31, depth31 := position, tokenIndex, depth
+			position58, tokenIndex58, depth58 := position, tokenIndex, depth
 			{
-				position32 := position
+				position59 := position
 				depth++
 				if !rules[RuleLevel0]() {
-					goto l31
+					goto l58
 				}
 				if !rules[Rulews]() {
-					goto l31
+					goto l58
 				}
 				if buffer[position] != '-' {
-					goto l31
+					goto l58
 				}
 				position++
 				if !rules[Rulews]() {
-					goto l31
+					goto l58
 				}
 				if !rules[RuleLevel1]() {
-					goto l31
+					goto l58
 				}
 				depth--
-				add(RuleSubtraction, position32)
+				add(RuleSubtraction, position59)
 			}
 			return true
-		l31:
-			position, tokenIndex, depth = position31, tokenIndex31, depth31
+		l58:
+			position, tokenIndex, depth = position58, tokenIndex58, depth58
 			return false
 		},
-		/* 8 Level0 <- <(Grouped / Call / Boolean / String / Integer / List / Merge / Auto / Reference)> */
+		/* 10 Level0 <- <(Grouped / Call / Boolean / String / Integer / List / Merge / Auto / Import / Reference)> */
 		func() bool {
-			position33, tokenIndex33, depth33 := position, tokenIndex, depth
+			position60, tokenIndex60, depth60 := position, tokenIndex, depth
 			{
-				position34 := position
+				position61 := position
 				depth++
 				{
-					position35, tokenIndex35, depth35 := position, tokenIndex, depth
+					position62, tokenIndex62, depth62 := position, tokenIndex, depth
 					if !rules[RuleGrouped]() {
-						goto l36
+						goto l63
 					}
-					goto l35
-				l36:
-					position, tokenIndex, depth = position35, tokenIndex35, depth35
+					goto l62
+				l63:
+					position, tokenIndex, depth = position62, tokenIndex62, depth62
 					if !rules[RuleCall]() {
-						goto l37
+						goto l64
 					}
-					goto l35
-				l37:
-					position, tokenIndex, depth = position35, tokenIndex35, depth35
+					goto l62
+				l64:
+					position, tokenIndex, depth = position62, tokenIndex62, depth62
 					if !rules[RuleBoolean]() {
-						goto l38
+						goto l65
 					}
-					goto l35
-				l38:
-					position, tokenIndex, depth = position35, tokenIndex35, depth35
+					goto l62
+				l65:
+					position, tokenIndex, depth = position62, tokenIndex62, depth62
 					if !rules[RuleString]() {
-						goto l39
+						goto l66
 					}
-					goto l35
-				l39:
-					position, tokenIndex, depth = position35, tokenIndex35, depth35
+					goto l62
+				l66:
+					position, tokenIndex, depth = position62, tokenIndex62, depth62
 					if !rules[RuleInteger]() {
-						goto l40
+						goto l67
 					}
-					goto l35
-				l40:
-					position, tokenIndex, depth = position35, tokenIndex35, depth35
+					goto l62
+				l67:
+					position, tokenIndex, depth = position62, tokenIndex62, depth62
 					if !rules[RuleList]() {
-						goto l41
+						goto l68
 					}
-					goto l35
-				l41:
-					position, tokenIndex, depth = position35, tokenIndex35, depth35
+					goto l62
+				l68:
+					position, tokenIndex, depth = position62, tokenIndex62, depth62
 					if !rules[RuleMerge]() {
-						goto l42
+						goto l69
 					}
-					goto l35
-				l42:
-					position, tokenIndex, depth = position35, tokenIndex35, depth35
+					goto l62
+				l69:
+					position, tokenIndex, depth = position62, tokenIndex62, depth62
 					if !rules[RuleAuto]() {
-						goto l43
+						goto l70
 					}
-					goto l35
-				l43:
-					position, tokenIndex, depth = position35, tokenIndex35, depth35
+					goto l62
+				l70:
+					position, tokenIndex, depth = position62, tokenIndex62, depth62
+					if !rules[RuleImport]() {
+						goto l71
+					}
+					goto l62
+				l71:
+					position, tokenIndex, depth = position62, tokenIndex62, depth62
 					if !rules[RuleReference]() {
-						goto l33
+						goto l60
 					}
 				}
-			l35:
+			l62:
 				depth--
-				add(RuleLevel0, position34)
+				add(RuleLevel0, position61)
 			}
 			return true
-		l33:
-			position, tokenIndex, depth = position33, tokenIndex33, depth33
+		l60:
+			position, tokenIndex, depth = position60, tokenIndex60, depth60
 			return false
 		},
-		/* 9 Grouped <- <('(' Expression ')')> */
+		/* 11 Grouped <- <('(' Expression ')')> */
 		func() bool {
-			position44, tokenIndex44, depth44 := position, tokenIndex, depth
+			position72, tokenIndex72, depth72 := position, tokenIndex, depth
 			{
-				position45 := position
+				position73 := position
 				depth++
 				if buffer[position] != '(' {
-					goto l44
+					goto l72
 				}
 				position++
 				if !rules[RuleExpression]() {
-					goto l44
+					goto l72
 				}
 				if buffer[position] != ')' {
-					goto l44
+					goto l72
 				}
 				position++
 				depth--
-				add(RuleGrouped, position45)
+				add(RuleGrouped, position73)
 			}
 			return true
-		l44:
-			position, tokenIndex, depth = position44, tokenIndex44, depth44
+		l72:
+			position, tokenIndex, depth = position72, tokenIndex72, depth72
 			return false
 		},
-		/* 10 Call <- <(Name '(' Arguments ')')> */
+		/* 12 Call <- <(Name '(' Arguments ')')> */
 		func() bool {
-			position46, tokenIndex46, depth46 := position, tokenIndex, depth
+			position74, tokenIndex74, depth74 := position, tokenIndex, depth
 			{
-				position47 := position
+				position75 := position
 				depth++
 				if !rules[RuleName]() {
-					goto l46
+					goto l74
 				}
 				if buffer[position] != '(' {
-					goto l46
+					goto l74
 				}
 				position++
 				if !rules[RuleArguments]() {
-					goto l46
+					goto l74
 				}
 				if buffer[position] != ')' {
-					goto l46
+					goto l74
 				}
 				position++
 				depth--
-				add(RuleCall, position47)
+				add(RuleCall, position75)
 			}
 			return true
-		l46:
-			position, tokenIndex, depth = position46, tokenIndex46, depth46
+		l74:
+			position, tokenIndex, depth = position74, tokenIndex74, depth74
 			return false
 		},
-		/* 11 Arguments <- <(Expression (Comma ws Expression)*)> */
+		/* 13 Arguments <- <(Expression (Comma ws Expression)*)> */
 		func() bool {
-			position48, tokenIndex48, depth48 := position, tokenIndex, depth
+			position76, tokenIndex76, depth76 := position, tokenIndex, depth
 			{
-				position49 := position
+				position77 := position
 				depth++
 				if !rules[RuleExpression]() {
-					goto l48
+					goto l76
 				}
-			l50:
+			l78:
 				{
-					position51, tokenIndex51, depth51 := position, tokenIndex, depth
+					position79, tokenIndex79, depth79 := position, tokenIndex, depth
 					if !rules[RuleComma]() {
-						goto l51
+						goto l79
 					}
 					if !rules[Rulews]() {
-						goto l51
+						goto l79
 					}
 					if !rules[RuleExpression]() {
-						goto l51
+						goto l79
 					}
-					goto l50
-				l51:
-					position, tokenIndex, depth = position51, tokenIndex51, depth51
+					goto l78
+				l79:
+					position, tokenIndex, depth = position79, tokenIndex79, depth79
 				}
 				depth--
-				add(RuleArguments, position49)
+				add(RuleArguments, position77)
 			}
 			return true
-		l48:
-			position, tokenIndex, depth = position48, tokenIndex48, depth48
+		l76:
+			position, tokenIndex, depth = position76, tokenIndex76, depth76
 			return false
 		},
-		/* 12 Name <- <([a-z] / [A-Z] / [0-9] / '_')+> */
+		/* 14 Name <- <([a-z] / [A-Z] / [0-9] / '_')+> */
 		func() bool {
-			position52, tokenIndex52, depth52 := position, tokenIndex, depth
+			position80, tokenIndex80, depth80 := position, tokenIndex, depth
 			{
-				position53 := position
+				position81 := position
 				depth++
 				{
-					position56, tokenIndex56, depth56 := position, tokenIndex, depth
+					position84, tokenIndex84, depth84 := position, tokenIndex, depth
 					if c := buffer[position]; c < 'a' || c > 'z' {
-						goto l57
+						goto l85
 					}
 					position++
-					goto l56
-				l57:
-					position, tokenIndex, depth = position56, tokenIndex56, depth56
+					goto l84
+				l85:
+					position, tokenIndex, depth = position84, tokenIndex84, depth84
 					if c := buffer[position]; c < 'A' || c > 'Z' {
-						goto l58
+						goto l86
 					}
 					position++
-					goto l56
-				l58:
-					position, tokenIndex, depth = position56, tokenIndex56, depth56
+					goto l84
+				l86:
+					position, tokenIndex, depth = position84, tokenIndex84, depth84
 					if c := buffer[position]; c < '0' || c > '9' {
-						goto l59
+						goto l87
 					}
 					position++
-					goto l56
-				l59:
-					position, tokenIndex, depth = position56, tokenIndex56, depth56
+					goto l84
+				l87:
+					position, tokenIndex, depth = position84, tokenIndex84, depth84
 					if buffer[position] != '_' {
-						goto l52
+						goto l80
 					}
 					position++
 				}
-			l56:
-			l54:
+			l84:
+			l82:
 				{
-					position55, tokenIndex55, depth55 := position, tokenIndex, depth
+					position83, tokenIndex83, depth83 := position, tokenIndex, depth
 					{
-						position60, tokenIndex60, depth60 := position, tokenIndex, depth
+						position88, tokenIndex88, depth88 := position, tokenIndex, depth
 						if c := buffer[position]; c < 'a' || c > 'z' {
-							goto l61
+							goto l89
 						}
 						position++
-						goto l60
-					l61:
-						position, tokenIndex, depth = position60, tokenIndex60, depth60
+						goto l88
+					l89:
+						position, tokenIndex, depth = position88, tokenIndex88, depth88
 						if c := buffer[position]; c < 'A' || c > 'Z' {
-							goto l62
+							goto l90
 						}
 						position++
-						goto l60
-					l62:
-						position, tokenIndex, depth = position60, tokenIndex60, depth60
+						goto l88
+					l90:
+						position, tokenIndex, depth = position88, tokenIndex88, depth88
 						if c := buffer[position]; c < '0' || c > '9' {
-							goto l63
+							goto l91
 						}
 						position++
-						goto l60
-					l63:
-						position, tokenIndex, depth = position60, tokenIndex60, depth60
+						goto l88
+					l91:
+						position, tokenIndex, depth = position88, tokenIndex88, depth88
 						if buffer[position] != '_' {
-							goto l55
+							goto l83
 						}
 						position++
 					}
-				l60:
-					goto l54
-				l55:
-					position, tokenIndex, depth = position55, tokenIndex55, depth55
+				l88:
+					goto l82
+				l83:
+					position, tokenIndex, depth = position83, tokenIndex83, depth83
 				}
 				depth--
-				add(RuleName, position53)
+				add(RuleName, position81)
 			}
 			return true
-		l52:
-			position, tokenIndex, depth = position52, tokenIndex52, depth52
+		l80:
+			position, tokenIndex, depth = position80, tokenIndex80, depth80
 			return false
 		},
-		/* 13 Comma <- <','> */
+		/* 15 Comma <- <','> */
 		func() bool {
-			position64, tokenIndex64, depth64 := position, tokenIndex, depth
+			position92, tokenIndex92, depth92 := position, tokenIndex, depth
 			{
-				position65 := position
+				position93 := position
 				depth++
 				if buffer[position] != ',' {
-					goto l64
+					goto l92
 				}
 				position++
 				depth--
-				add(RuleComma, position65)
+				add(RuleComma, position93)
 			}
 			return true
-		l64:
-			position, tokenIndex, depth = position64, tokenIndex64, depth64
+		l92:
+			position, tokenIndex, depth = position92, tokenIndex92, depth92
 			return false
 		},
-		/* 14 Integer <- <([0-9] / '_')+> */
+		/* 16 Integer <- <([0-9] / '_')+> */
 		func() bool {
-			position66, tokenIndex66, depth66 := position, tokenIndex, depth
+			position94, tokenIndex94, depth94 := position, tokenIndex, depth
 			{
-				position67 := position
+				position95 := position
 				depth++
 				{
-					position70, tokenIndex70, depth70 := position, tokenIndex, depth
+					position98, tokenIndex98, depth98 := position, tokenIndex, depth
 					if c := buffer[position]; c < '0' || c > '9' {
-						goto l71
+						goto l99
 					}
 					position++
-					goto l70
-				l71:
-					position, tokenIndex, depth = position70, tokenIndex70, depth70
+					goto l98
+				l99:
+					position, tokenIndex, depth = position98, tokenIndex98, depth98
 					if buffer[position] != '_' {
-						goto l66
+						goto l94
 					}
 					position++
 				}
-			l70:
-			l68:
+			l98:
+			l96:
 				{
-					position69, tokenIndex69, depth69 := position, tokenIndex, depth
+					position97, tokenIndex97, depth97 := position, tokenIndex, depth
 					{
-						position72, tokenIndex72, depth72 := position, tokenIndex, depth
+						position100, tokenIndex100, depth100 := position, tokenIndex, depth
 						if c := buffer[position]; c < '0' || c > '9' {
-							goto l73
+							goto l101
 						}
 						position++
-						goto l72
-					l73:
-						position, tokenIndex, depth = position72, tokenIndex72, depth72
+						goto l100
+					l101:
+						position, tokenIndex, depth = position100, tokenIndex100, depth100
 						if buffer[position] != '_' {
-							goto l69
+							goto l97
 						}
 						position++
 					}
-				l72:
-					goto l68
-				l69:
-					position, tokenIndex, depth = position69, tokenIndex69, depth69
+				l100:
+					goto l96
+				l97:
+					position, tokenIndex, depth = position97, tokenIndex97, depth97
 				}
 				depth--
-				add(RuleInteger, position67)
+				add(RuleInteger, position95)
 			}
 			return true
-		l66:
-			position, tokenIndex, depth = position66, tokenIndex66, depth66
+		l94:
+			position, tokenIndex, depth = position94, tokenIndex94, depth94
 			return false
 		},
-		/* 15 String <- <('"' (!'"' .)* '"')> */
+		/* 17 String <- <('"' (!'"' .)* '"')> */
 		func() bool {
-			position74, tokenIndex74, depth74 := position, tokenIndex, depth
+			position102, tokenIndex102, depth102 := position, tokenIndex, depth
 			{
-				position75 := position
+				position103 := position
 				depth++
 				if buffer[position] != '"' {
-					goto l74
+					goto l102
 				}
 				position++
-			l76:
+			l104:
 				{
-					position77, tokenIndex77, depth77 := position, tokenIndex, depth
+					position105, tokenIndex105, depth105 := position, tokenIndex, depth
 					{
-						position78, tokenIndex78, depth78 := position, tokenIndex, depth
+						position106, tokenIndex106, depth106 := position, tokenIndex, depth
 						if buffer[position] != '"' {
-							goto l78
+							goto l106
 						}
 						position++
-						goto l77
-					l78:
-						position, tokenIndex, depth = position78, tokenIndex78, depth78
+						goto l105
+					l106:
+						position, tokenIndex, depth = position106, tokenIndex106, depth106
 					}
 					if !matchDot() {
-						goto l77
+						goto l105
 					}
-					goto l76
-				l77:
-					position, tokenIndex, depth = position77, tokenIndex77, depth77
+					goto l104
+				l105:
+					position, tokenIndex, depth = position105, tokenIndex105, depth105
 				}
 				if buffer[position] != '"' {
-					goto l74
+					goto l102
 				}
 				position++
 				depth--
-				add(RuleString, position75)
+				add(RuleString, position103)
 			}
 			return true
-		l74:
-			position, tokenIndex, depth = position74, tokenIndex74, depth74
+		l102:
+			position, tokenIndex, depth = position102, tokenIndex102, depth102
 			return false
 		},
-		/* 16 Boolean <- <(('t' 'r' 'u' 'e') / ('f' 'a' 'l' 's' 'e'))> */
+		/* 18 Boolean <- <(('t' 'r' 'u' 'e') / ('f' 'a' 'l' 's' 'e'))> */
 		func() bool {
-			position79, tokenIndex79, depth79 := position, tokenIndex, depth
+			position107, tokenIndex107, depth107 := position, tokenIndex, depth
 			{
-				position80 := position
+				position108 := position
 				depth++
 				{
-					position81, tokenIndex81, depth81 := position, tokenIndex, depth
+					position109, tokenIndex109, depth109 := position, tokenIndex, depth
 					if buffer[position] != 't' {
-						goto l82
+						goto l110
 					}
 					position++
 					if buffer[position] != 'r' {
-						goto l82
+						goto l110
 					}
 					position++
 					if buffer[position] != 'u' {
-						goto l82
+						goto l110
 					}
 					position++
 					if buffer[position] != 'e' {
-						goto l82
+						goto l110
 					}
 					position++
-					goto l81
-				l82:
-					position, tokenIndex, depth = position81, tokenIndex81, depth81
+					goto l109
+				l110:
+					position, tokenIndex, depth = position109, tokenIndex109, depth109
 					if buffer[position] != 'f' {
-						goto l79
+						goto l107
 					}
 					position++
 					if buffer[position] != 'a' {
-						goto l79
+						goto l107
 					}
 					position++
 					if buffer[position] != 'l' {
-						goto l79
+						goto l107
 					}
 					position++
 					if buffer[position] != 's' {
-						goto l79
+						goto l107
 					}
 					position++
 					if buffer[position] != 'e' {
-						goto l79
+						goto l107
 					}
 					position++
 				}
-			l81:
+			l109:
 				depth--
-				add(RuleBoolean, position80)
+				add(RuleBoolean, position108)
 			}
 			return true
-		l79:
-			position, tokenIndex, depth = position79, tokenIndex79, depth79
+		l107:
+			position, tokenIndex, depth = position107, tokenIndex107, depth107
 			return false
 		},
-		/* 17 List <- <('[' Contents ']')> */
+		/* 19 List <- <('[' ws (Comprehension / Range / Contents / Empty) ws ']')> */
 		func() bool {
-			position83, tokenIndex83, depth83 := position, tokenIndex, depth
+			position111, tokenIndex111, depth111 := position, tokenIndex, depth
 			{
-				position84 := position
+				position112 := position
 				depth++
 				if buffer[position] != '[' {
-					goto l83
+					goto l111
 				}
 				position++
-				if !rules[RuleContents]() {
-					goto l83
+				if !rules[Rulews]() {
+					goto l111
+				}
+				{
+					position113, tokenIndex113, depth113 := position, tokenIndex, depth
+					if !rules[RuleComprehension]() {
+						goto l114
+					}
+					goto l113
+				l114:
+					position, tokenIndex, depth = position113, tokenIndex113, depth113
+					if !rules[RuleRange]() {
+						goto l115
+					}
+					goto l113
+				l115:
+					position, tokenIndex, depth = position113, tokenIndex113, depth113
+					if !rules[RuleContents]() {
+						goto l116
+					}
+					goto l113
+				l116:
+					position, tokenIndex, depth = position113, tokenIndex113, depth113
+					if !rules[RuleEmpty]() {
+						goto l111
+					}
+				}
+			l113:
+				if !rules[Rulews]() {
+					goto l111
 				}
 				if buffer[position] != ']' {
-					goto l83
+					goto l111
 				}
 				position++
 				depth--
-				add(RuleList, position84)
+				add(RuleList, position112)
 			}
 			return true
-		l83:
-			position, tokenIndex, depth = position83, tokenIndex83, depth83
+		l111:
+			position, tokenIndex, depth = position111, tokenIndex111, depth111
 			return false
 		},
-		/* 18 Contents <- <(Expression (Comma ws Expression)*)> */
+		/* 20 Contents <- <(Expression Spread? (Comma ws Expression Spread?)*)> */
 		func() bool {
-			position85, tokenIndex85, depth85 := position, tokenIndex, depth
+			position117, tokenIndex117, depth117 := position, tokenIndex, depth
 			{
-				position86 := position
+				position118 := position
 				depth++
 				if !rules[RuleExpression]() {
-					goto l85
+					goto l117
+				}
+				{
+					position119, tokenIndex119, depth119 := position, tokenIndex, depth
+					if !rules[RuleSpread]() {
+						goto l119
+					}
+					goto l120
+				l119:
+					position, tokenIndex, depth = position119, tokenIndex119, depth119
 				}
-			l87:
+			l120:
+			l121:
 				{
-					position88, tokenIndex88, depth88 := position, tokenIndex, depth
+					position122, tokenIndex122, depth122 := position, tokenIndex, depth
 					if !rules[RuleComma]() {
-						goto l88
+						goto l122
 					}
 					if !rules[Rulews]() {
-						goto l88
+						goto l122
 					}
 					if !rules[RuleExpression]() {
-						goto l88
+						goto l122
 					}
-					goto l87
-				l88:
-					position, tokenIndex, depth = position88, tokenIndex88, depth88
+					{
+						position123, tokenIndex123, depth123 := position, tokenIndex, depth
+						if !rules[RuleSpread]() {
+							goto l123
+						}
+						goto l124
+					l123:
+						position, tokenIndex, depth = position123, tokenIndex123, depth123
+					}
+				l124:
+					goto l121
+				l122:
+					position, tokenIndex, depth = position122, tokenIndex122, depth122
+				}
+				depth--
+				add(RuleContents, position118)
+			}
+			return true
+		l117:
+			position, tokenIndex, depth = position117, tokenIndex117, depth117
+			return false
+		},
+		/* 21 Spread <- <('.' '.' '.')> */
+		func() bool {
+			position125, tokenIndex125, depth125 := position, tokenIndex, depth
+			{
+				position126 := position
+				depth++
+				if buffer[position] != '.' {
+					goto l125
+				}
+				position++
+				if buffer[position] != '.' {
+					goto l125
+				}
+				position++
+				if buffer[position] != '.' {
+					goto l125
+				}
+				position++
+				depth--
+				add(RuleSpread, position126)
+			}
+			return true
+		l125:
+			position, tokenIndex, depth = position125, tokenIndex125, depth125
+			return false
+		},
+		/* 22 Empty <- <&']'> */
+		func() bool {
+			position127, tokenIndex127, depth127 := position, tokenIndex, depth
+			{
+				position128 := position
+				depth++
+				{
+					position129, tokenIndex129, depth129 := position, tokenIndex, depth
+					if buffer[position] != ']' {
+						goto l127
+					}
+					position++
+					position, tokenIndex, depth = position129, tokenIndex129, depth129
 				}
 				depth--
-				add(RuleContents, position86)
+				add(RuleEmpty, position128)
 			}
 			return true
-		l85:
-			position, tokenIndex, depth = position85, tokenIndex85, depth85
+		l127:
+			position, tokenIndex, depth = position127, tokenIndex127, depth127
 			return false
 		},
-		/* 19 Merge <- <('m' 'e' 'r' 'g' 'e')> */
+		/* 23 Range <- <(Expression ws ('.' '.') ws Expression)> */
 		func() bool {
-			position89, tokenIndex89, depth89 := position, tokenIndex, depth
+			position130, tokenIndex130, depth130 := position, tokenIndex, depth
 			{
-				position90 := position
+				position131 := position
+				depth++
+				if !rules[RuleExpression]() {
+					goto l130
+				}
+				if !rules[Rulews]() {
+					goto l130
+				}
+				if buffer[position] != '.' {
+					goto l130
+				}
+				position++
+				if buffer[position] != '.' {
+					goto l130
+				}
+				position++
+				if !rules[Rulews]() {
+					goto l130
+				}
+				if !rules[RuleExpression]() {
+					goto l130
+				}
+				depth--
+				add(RuleRange, position131)
+			}
+			return true
+		l130:
+			position, tokenIndex, depth = position130, tokenIndex130, depth130
+			return false
+		},
+		/* 24 Comprehension <- <(Expression ws ('f' 'o' 'r') ws Variable ws ('i' 'n') ws Expression)> */
+		func() bool {
+			position132, tokenIndex132, depth132 := position, tokenIndex, depth
+			{
+				position133 := position
+				depth++
+				if !rules[RuleExpression]() {
+					goto l132
+				}
+				if !rules[Rulews]() {
+					goto l132
+				}
+				if buffer[position] != 'f' {
+					goto l132
+				}
+				position++
+				if buffer[position] != 'o' {
+					goto l132
+				}
+				position++
+				if buffer[position] != 'r' {
+					goto l132
+				}
+				position++
+				if !rules[Rulews]() {
+					goto l132
+				}
+				if !rules[RuleVariable]() {
+					goto l132
+				}
+				if !rules[Rulews]() {
+					goto l132
+				}
+				if buffer[position] != 'i' {
+					goto l132
+				}
+				position++
+				if buffer[position] != 'n' {
+					goto l132
+				}
+				position++
+				if !rules[Rulews]() {
+					goto l132
+				}
+				if !rules[RuleExpression]() {
+					goto l132
+				}
+				depth--
+				add(RuleComprehension, position133)
+			}
+			return true
+		l132:
+			position, tokenIndex, depth = position132, tokenIndex132, depth132
+			return false
+		},
+		/* 25 Variable <- <([a-z] / [A-Z] / [0-9] / '_')+> */
+		func() bool {
+			position134, tokenIndex134, depth134 := position, tokenIndex, depth
+			{
+				position135 := position
+				depth++
+				{
+					position138, tokenIndex138, depth138 := position, tokenIndex, depth
+					if c := buffer[position]; c < 'a' || c > 'z' {
+						goto l139
+					}
+					position++
+					goto l138
+				l139:
+					position, tokenIndex, depth = position138, tokenIndex138, depth138
+					if c := buffer[position]; c < 'A' || c > 'Z' {
+						goto l140
+					}
+					position++
+					goto l138
+				l140:
+					position, tokenIndex, depth = position138, tokenIndex138, depth138
+					if c := buffer[position]; c < '0' || c > '9' {
+						goto l141
+					}
+					position++
+					goto l138
+				l141:
+					position, tokenIndex, depth = position138, tokenIndex138, depth138
+					if buffer[position] != '_' {
+						goto l134
+					}
+					position++
+				}
+			l138:
+			l136:
+				{
+					position137, tokenIndex137, depth137 := position, tokenIndex, depth
+					{
+						position142, tokenIndex142, depth142 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l143
+						}
+						position++
+						goto l142
+					l143:
+						position, tokenIndex, depth = position142, tokenIndex142, depth142
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l144
+						}
+						position++
+						goto l142
+					l144:
+						position, tokenIndex, depth = position142, tokenIndex142, depth142
+						if c := buffer[position]; c < '0' || c > '9' {
+							goto l145
+						}
+						position++
+						goto l142
+					l145:
+						position, tokenIndex, depth = position142, tokenIndex142, depth142
+						if buffer[position] != '_' {
+							goto l137
+						}
+						position++
+					}
+				l142:
+					goto l136
+				l137:
+					position, tokenIndex, depth = position137, tokenIndex137, depth137
+				}
+				depth--
+				add(RuleVariable, position135)
+			}
+			return true
+		l134:
+			position, tokenIndex, depth = position134, tokenIndex134, depth134
+			return false
+		},
+		/* 26 Keyword <- <((('f' 'o' 'r') / ('i' 'n') / ('i' 'f')) !([a-z] / [A-Z] / [0-9] / '_'))> */
+		func() bool {
+			position146, tokenIndex146, depth146 := position, tokenIndex, depth
+			{
+				position147 := position
+				depth++
+				{
+					position148, tokenIndex148, depth148 := position, tokenIndex, depth
+					if buffer[position] != 'f' {
+						goto l149
+					}
+					position++
+					if buffer[position] != 'o' {
+						goto l149
+					}
+					position++
+					if buffer[position] != 'r' {
+						goto l149
+					}
+					position++
+					goto l148
+				l149:
+					position, tokenIndex, depth = position148, tokenIndex148, depth148
+					if buffer[position] != 'i' {
+						goto l150
+					}
+					position++
+					if buffer[position] != 'n' {
+						goto l150
+					}
+					position++
+					goto l148
+				l150:
+					position, tokenIndex, depth = position148, tokenIndex148, depth148
+					if buffer[position] != 'i' {
+						goto l146
+					}
+					position++
+					if buffer[position] != 'f' {
+						goto l146
+					}
+					position++
+				}
+			l148:
+				{
+					position151, tokenIndex151, depth151 := position, tokenIndex, depth
+					{
+						position152, tokenIndex152, depth152 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l153
+						}
+						position++
+						goto l152
+					l153:
+						position, tokenIndex, depth = position152, tokenIndex152, depth152
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l154
+						}
+						position++
+						goto l152
+					l154:
+						position, tokenIndex, depth = position152, tokenIndex152, depth152
+						if c := buffer[position]; c < '0' || c > '9' {
+							goto l155
+						}
+						position++
+						goto l152
+					l155:
+						position, tokenIndex, depth = position152, tokenIndex152, depth152
+						if buffer[position] != '_' {
+							goto l151
+						}
+						position++
+					}
+				l152:
+					goto l146
+				l151:
+					position, tokenIndex, depth = position151, tokenIndex151, depth151
+				}
+				depth--
+				add(RuleKeyword, position147)
+			}
+			return true
+		l146:
+			position, tokenIndex, depth = position146, tokenIndex146, depth146
+			return false
+		},
+		/* 27 Merge <- <(('m' 'e' 'r' 'g' 'e') ((' ' / '\t')+ (Strategy / MergePath))?)> */
+		func() bool {
+			position156, tokenIndex156, depth156 := position, tokenIndex, depth
+			{
+				position157 := position
 				depth++
 				if buffer[position] != 'm' {
-					goto l89
+					goto l156
 				}
 				position++
 				if buffer[position] != 'e' {
-					goto l89
+					goto l156
 				}
 				position++
 				if buffer[position] != 'r' {
-					goto l89
+					goto l156
 				}
 				position++
 				if buffer[position] != 'g' {
-					goto l89
+					goto l156
 				}
 				position++
 				if buffer[position] != 'e' {
-					goto l89
+					goto l156
 				}
 				position++
+				{
+					position158, tokenIndex158, depth158 := position, tokenIndex, depth
+					{
+						position162, tokenIndex162, depth162 := position, tokenIndex, depth
+						if buffer[position] != ' ' {
+							goto l163
+						}
+						position++
+						goto l162
+					l163:
+						position, tokenIndex, depth = position162, tokenIndex162, depth162
+						if buffer[position] != '\t' {
+							goto l158
+						}
+						position++
+					}
+				l162:
+				l160:
+					{
+						position161, tokenIndex161, depth161 := position, tokenIndex, depth
+						{
+							position164, tokenIndex164, depth164 := position, tokenIndex, depth
+							if buffer[position] != ' ' {
+								goto l165
+							}
+							position++
+							goto l164
+						l165:
+							position, tokenIndex, depth = position164, tokenIndex164, depth164
+							if buffer[position] != '\t' {
+								goto l161
+							}
+							position++
+						}
+					l164:
+						goto l160
+					l161:
+						position, tokenIndex, depth = position161, tokenIndex161, depth161
+					}
+					{
+						position166, tokenIndex166, depth166 := position, tokenIndex, depth
+						if !rules[RuleStrategy]() {
+							goto l167
+						}
+						goto l166
+					l167:
+						position, tokenIndex, depth = position166, tokenIndex166, depth166
+						if !rules[RuleMergePath]() {
+							goto l158
+						}
+					}
+				l166:
+					goto l159
+				l158:
+					position, tokenIndex, depth = position158, tokenIndex158, depth158
+				}
+			l159:
+				depth--
+				add(RuleMerge, position157)
+			}
+			return true
+		l156:
+			position, tokenIndex, depth = position156, tokenIndex156, depth156
+			return false
+		},
+		/* 28 MergePath <- <(!Keyword ([a-z] / [A-Z] / [0-9] / '_')+ ('.' ([a-z] / [A-Z] / [0-9] / '_')+)*)> */
+		func() bool {
+			position168, tokenIndex168, depth168 := position, tokenIndex, depth
+			{
+				position169 := position
+				depth++
+				{
+					position170, tokenIndex170, depth170 := position, tokenIndex, depth
+					if !rules[RuleKeyword]() {
+						goto l170
+					}
+					goto l168
+				l170:
+					position, tokenIndex, depth = position170, tokenIndex170, depth170
+				}
+				{
+					position173, tokenIndex173, depth173 := position, tokenIndex, depth
+					if c := buffer[position]; c < 'a' || c > 'z' {
+						goto l174
+					}
+					position++
+					goto l173
+				l174:
+					position, tokenIndex, depth = position173, tokenIndex173, depth173
+					if c := buffer[position]; c < 'A' || c > 'Z' {
+						goto l175
+					}
+					position++
+					goto l173
+				l175:
+					position, tokenIndex, depth = position173, tokenIndex173, depth173
+					if c := buffer[position]; c < '0' || c > '9' {
+						goto l176
+					}
+					position++
+					goto l173
+				l176:
+					position, tokenIndex, depth = position173, tokenIndex173, depth173
+					if buffer[position] != '_' {
+						goto l168
+					}
+					position++
+				}
+			l173:
+			l171:
+				{
+					position172, tokenIndex172, depth172 := position, tokenIndex, depth
+					{
+						position177, tokenIndex177, depth177 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l178
+						}
+						position++
+						goto l177
+					l178:
+						position, tokenIndex, depth = position177, tokenIndex177, depth177
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l179
+						}
+						position++
+						goto l177
+					l179:
+						position, tokenIndex, depth = position177, tokenIndex177, depth177
+						if c := buffer[position]; c < '0' || c > '9' {
+							goto l180
+						}
+						position++
+						goto l177
+					l180:
+						position, tokenIndex, depth = position177, tokenIndex177, depth177
+						if buffer[position] != '_' {
+							goto l172
+						}
+						position++
+					}
+				l177:
+					goto l171
+				l172:
+					position, tokenIndex, depth = position172, tokenIndex172, depth172
+				}
+			l181:
+				{
+					position182, tokenIndex182, depth182 := position, tokenIndex, depth
+					if buffer[position] != '.' {
+						goto l182
+					}
+					position++
+					{
+						position185, tokenIndex185, depth185 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l186
+						}
+						position++
+						goto l185
+					l186:
+						position, tokenIndex, depth = position185, tokenIndex185, depth185
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l187
+						}
+						position++
+						goto l185
+					l187:
+						position, tokenIndex, depth = position185, tokenIndex185, depth185
+						if c := buffer[position]; c < '0' || c > '9' {
+							goto l188
+						}
+						position++
+						goto l185
+					l188:
+						position, tokenIndex, depth = position185, tokenIndex185, depth185
+						if buffer[position] != '_' {
+							goto l182
+						}
+						position++
+					}
+				l185:
+				l183:
+					{
+						position184, tokenIndex184, depth184 := position, tokenIndex, depth
+						{
+							position189, tokenIndex189, depth189 := position, tokenIndex, depth
+							if c := buffer[position]; c < 'a' || c > 'z' {
+								goto l190
+							}
+							position++
+							goto l189
+						l190:
+							position, tokenIndex, depth = position189, tokenIndex189, depth189
+							if c := buffer[position]; c < 'A' || c > 'Z' {
+								goto l191
+							}
+							position++
+							goto l189
+						l191:
+							position, tokenIndex, depth = position189, tokenIndex189, depth189
+							if c := buffer[position]; c < '0' || c > '9' {
+								goto l192
+							}
+							position++
+							goto l189
+						l192:
+							position, tokenIndex, depth = position189, tokenIndex189, depth189
+							if buffer[position] != '_' {
+								goto l184
+							}
+							position++
+						}
+					l189:
+						goto l183
+					l184:
+						position, tokenIndex, depth = position184, tokenIndex184, depth184
+					}
+					goto l181
+				l182:
+					position, tokenIndex, depth = position182, tokenIndex182, depth182
+				}
 				depth--
-				add(RuleMerge, position90)
+				add(RuleMergePath, position169)
 			}
 			return true
-		l89:
-			position, tokenIndex, depth = position89, tokenIndex89, depth89
+		l168:
+			position, tokenIndex, depth = position168, tokenIndex168, depth168
 			return false
 		},
-		/* 20 Auto <- <('a' 'u' 't' 'o')> */
+		/* 29 Strategy <- <((('a' 'p' 'p' 'e' 'n' 'd') / ('p' 'r' 'e' 'p' 'e' 'n' 'd') / ('r' 'e' 'p' 'l' 'a' 'c' 'e') / (('o' 'n') (' ' / '\t')+ ([a-z] / [A-Z] / [0-9] / '_')+)) !([a-z] / [A-Z] / [0-9] / '_'))> */
 		func() bool {
-			position91, tokenIndex91, depth91 := position, tokenIndex, depth
+			position193, tokenIndex193, depth193 := position, tokenIndex, depth
 			{
-				position92 := position
+				position194 := position
+				depth++
+				{
+					position195, tokenIndex195, depth195 := position, tokenIndex, depth
+					if buffer[position] != 'a' {
+						goto l196
+					}
+					position++
+					if buffer[position] != 'p' {
+						goto l196
+					}
+					position++
+					if buffer[position] != 'p' {
+						goto l196
+					}
+					position++
+					if buffer[position] != 'e' {
+						goto l196
+					}
+					position++
+					if buffer[position] != 'n' {
+						goto l196
+					}
+					position++
+					if buffer[position] != 'd' {
+						goto l196
+					}
+					position++
+					goto l195
+				l196:
+					position, tokenIndex, depth = position195, tokenIndex195, depth195
+					if buffer[position] != 'p' {
+						goto l197
+					}
+					position++
+					if buffer[position] != 'r' {
+						goto l197
+					}
+					position++
+					if buffer[position] != 'e' {
+						goto l197
+					}
+					position++
+					if buffer[position] != 'p' {
+						goto l197
+					}
+					position++
+					if buffer[position] != 'e' {
+						goto l197
+					}
+					position++
+					if buffer[position] != 'n' {
+						goto l197
+					}
+					position++
+					if buffer[position] != 'd' {
+						goto l197
+					}
+					position++
+					goto l195
+				l197:
+					position, tokenIndex, depth = position195, tokenIndex195, depth195
+					if buffer[position] != 'r' {
+						goto l198
+					}
+					position++
+					if buffer[position] != 'e' {
+						goto l198
+					}
+					position++
+					if buffer[position] != 'p' {
+						goto l198
+					}
+					position++
+					if buffer[position] != 'l' {
+						goto l198
+					}
+					position++
+					if buffer[position] != 'a' {
+						goto l198
+					}
+					position++
+					if buffer[position] != 'c' {
+						goto l198
+					}
+					position++
+					if buffer[position] != 'e' {
+						goto l198
+					}
+					position++
+					goto l195
+				l198:
+					position, tokenIndex, depth = position195, tokenIndex195, depth195
+					if buffer[position] != 'o' {
+						goto l193
+					}
+					position++
+					if buffer[position] != 'n' {
+						goto l193
+					}
+					position++
+					{
+						position201, tokenIndex201, depth201 := position, tokenIndex, depth
+						if buffer[position] != ' ' {
+							goto l202
+						}
+						position++
+						goto l201
+					l202:
+						position, tokenIndex, depth = position201, tokenIndex201, depth201
+						if buffer[position] != '\t' {
+							goto l193
+						}
+						position++
+					}
+				l201:
+				l199:
+					{
+						position200, tokenIndex200, depth200 := position, tokenIndex, depth
+						{
+							position203, tokenIndex203, depth203 := position, tokenIndex, depth
+							if buffer[position] != ' ' {
+								goto l204
+							}
+							position++
+							goto l203
+						l204:
+							position, tokenIndex, depth = position203, tokenIndex203, depth203
+							if buffer[position] != '\t' {
+								goto l200
+							}
+							position++
+						}
+					l203:
+						goto l199
+					l200:
+						position, tokenIndex, depth = position200, tokenIndex200, depth200
+					}
+					{
+						position207, tokenIndex207, depth207 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l208
+						}
+						position++
+						goto l207
+					l208:
+						position, tokenIndex, depth = position207, tokenIndex207, depth207
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l209
+						}
+						position++
+						goto l207
+					l209:
+						position, tokenIndex, depth = position207, tokenIndex207, depth207
+						if c := buffer[position]; c < '0' || c > '9' {
+							goto l210
+						}
+						position++
+						goto l207
+					l210:
+						position, tokenIndex, depth = position207, tokenIndex207, depth207
+						if buffer[position] != '_' {
+							goto l193
+						}
+						position++
+					}
+				l207:
+				l205:
+					{
+						position206, tokenIndex206, depth206 := position, tokenIndex, depth
+						{
+							position211, tokenIndex211, depth211 := position, tokenIndex, depth
+							if c := buffer[position]; c < 'a' || c > 'z' {
+								goto l212
+							}
+							position++
+							goto l211
+						l212:
+							position, tokenIndex, depth = position211, tokenIndex211, depth211
+							if c := buffer[position]; c < 'A' || c > 'Z' {
+								goto l213
+							}
+							position++
+							goto l211
+						l213:
+							position, tokenIndex, depth = position211, tokenIndex211, depth211
+							if c := buffer[position]; c < '0' || c > '9' {
+								goto l214
+							}
+							position++
+							goto l211
+						l214:
+							position, tokenIndex, depth = position211, tokenIndex211, depth211
+							if buffer[position] != '_' {
+								goto l206
+							}
+							position++
+						}
+					l211:
+						goto l205
+					l206:
+						position, tokenIndex, depth = position206, tokenIndex206, depth206
+					}
+				}
+			l195:
+				{
+					position215, tokenIndex215, depth215 := position, tokenIndex, depth
+					{
+						position216, tokenIndex216, depth216 := position, tokenIndex, depth
+						if c := buffer[position]; c < 'a' || c > 'z' {
+							goto l217
+						}
+						position++
+						goto l216
+					l217:
+						position, tokenIndex, depth = position216, tokenIndex216, depth216
+						if c := buffer[position]; c < 'A' || c > 'Z' {
+							goto l218
+						}
+						position++
+						goto l216
+					l218:
+						position, tokenIndex, depth = position216, tokenIndex216, depth216
+						if c := buffer[position]; c < '0' || c > '9' {
+							goto l219
+						}
+						position++
+						goto l216
+					l219:
+						position, tokenIndex, depth = position216, tokenIndex216, depth216
+						if buffer[position] != '_' {
+							goto l215
+						}
+						position++
+					}
+				l216:
+					goto l193
+				l215:
+					position, tokenIndex, depth = position215, tokenIndex215, depth215
+				}
+				depth--
+				add(RuleStrategy, position194)
+			}
+			return true
+		l193:
+			position, tokenIndex, depth = position193, tokenIndex193, depth193
+			return false
+		},
+		/* 30 Auto <- <('a' 'u' 't' 'o')> */
+		func() bool {
+			position220, tokenIndex220, depth220 := position, tokenIndex, depth
+			{
+				position221 := position
 				depth++
 				if buffer[position] != 'a' {
-					goto l91
+					goto l220
 				}
 				position++
 				if buffer[position] != 'u' {
-					goto l91
+					goto l220
 				}
 				position++
 				if buffer[position] != 't' {
-					goto l91
+					goto l220
 				}
 				position++
 				if buffer[position] != 'o' {
-					goto l91
+					goto l220
 				}
 				position++
 				depth--
-				add(RuleAuto, position92)
+				add(RuleAuto, position221)
 			}
 			return true
-		l91:
-			position, tokenIndex, depth = position91, tokenIndex91, depth91
+		l220:
+			position, tokenIndex, depth = position220, tokenIndex220, depth220
 			return false
 		},
-		/* 21 Reference <- <(([a-z] / [A-Z] / [0-9] / '_')+ ('.' ([a-z] / [A-Z] / [0-9] / '_')+)*)> */
+		/* 31 Import <- <(ImportKind (' ' / '\t')+ String)> */
 		func() bool {
-			position93, tokenIndex93, depth93 := position, tokenIndex, depth
+			position222, tokenIndex222, depth222 := position, tokenIndex, depth
 			{
-				position94 := position
+				position223 := position
 				depth++
+				if !rules[RuleImportKind]() {
+					goto l222
+				}
 				{
-					position97, tokenIndex97, depth97 := position, tokenIndex, depth
+					position226, tokenIndex226, depth226 := position, tokenIndex, depth
+					if buffer[position] != ' ' {
+						goto l227
+					}
+					position++
+					goto l226
+				l227:
+					position, tokenIndex, depth = position226, tokenIndex226, depth226
+					if buffer[position] != '\t' {
+						goto l222
+					}
+					position++
+				}
+			l226:
+			l224:
+				{
+					position225, tokenIndex225, depth225 := position, tokenIndex, depth
+					{
+						position228, tokenIndex228, depth228 := position, tokenIndex, depth
+						if buffer[position] != ' ' {
+							goto l229
+						}
+						position++
+						goto l228
+					l229:
+						position, tokenIndex, depth = position228, tokenIndex228, depth228
+						if buffer[position] != '\t' {
+							goto l225
+						}
+						position++
+					}
+				l228:
+					goto l224
+				l225:
+					position, tokenIndex, depth = position225, tokenIndex225, depth225
+				}
+				if !rules[RuleString]() {
+					goto l222
+				}
+				depth--
+				add(RuleImport, position223)
+			}
+			return true
+		l222:
+			position, tokenIndex, depth = position222, tokenIndex222, depth222
+			return false
+		},
+		/* 32 ImportKind <- <(('i' 'm' 'p' 'o' 'r' 't' '_' 't' 'e' 'x' 't') / ('i' 'm' 'p' 'o' 'r' 't'))> */
+		func() bool {
+			position230, tokenIndex230, depth230 := position, tokenIndex, depth
+			{
+				position231 := position
+				depth++
+				{
+					position232, tokenIndex232, depth232 := position, tokenIndex, depth
+					if buffer[position] != 'i' {
+						goto l233
+					}
+					position++
+					if buffer[position] != 'm' {
+						goto l233
+					}
+					position++
+					if buffer[position] != 'p' {
+						goto l233
+					}
+					position++
+					if buffer[position] != 'o' {
+						goto l233
+					}
+					position++
+					if buffer[position] != 'r' {
+						goto l233
+					}
+					position++
+					if buffer[position] != 't' {
+						goto l233
+					}
+					position++
+					if buffer[position] != '_' {
+						goto l233
+					}
+					position++
+					if buffer[position] != 't' {
+						goto l233
+					}
+					position++
+					if buffer[position] != 'e' {
+						goto l233
+					}
+					position++
+					if buffer[position] != 'x' {
+						goto l233
+					}
+					position++
+					if buffer[position] != 't' {
+						goto l233
+					}
+					position++
+					goto l232
+				l233:
+					position, tokenIndex, depth = position232, tokenIndex232, depth232
+					if buffer[position] != 'i' {
+						goto l230
+					}
+					position++
+					if buffer[position] != 'm' {
+						goto l230
+					}
+					position++
+					if buffer[position] != 'p' {
+						goto l230
+					}
+					position++
+					if buffer[position] != 'o' {
+						goto l230
+					}
+					position++
+					if buffer[position] != 'r' {
+						goto l230
+					}
+					position++
+					if buffer[position] != 't' {
+						goto l230
+					}
+					position++
+				}
+			l232:
+				depth--
+				add(RuleImportKind, position231)
+			}
+			return true
+		l230:
+			position, tokenIndex, depth = position230, tokenIndex230, depth230
+			return false
+		},
+		/* 33 Reference <- <(!Keyword ([a-z] / [A-Z] / [0-9] / '_')+ ('.' ([a-z] / [A-Z] / [0-9] / '_')+)*)> */
+		func() bool {
+			position234, tokenIndex234, depth234 := position, tokenIndex, depth
+			{
+				position235 := position
+				depth++
+				{
+					position236, tokenIndex236, depth236 := position, tokenIndex, depth
+					if !rules[RuleKeyword]() {
+						goto l236
+					}
+					goto l234
+				l236:
+					position, tokenIndex, depth = position236, tokenIndex236, depth236
+				}
+				{
+					position239, tokenIndex239, depth239 := position, tokenIndex, depth
 					if c := buffer[position]; c < 'a' || c > 'z' {
-						goto l98
+						goto l240
 					}
 					position++
-					goto l97
-				l98:
-					position, tokenIndex, depth = position97, tokenIndex97, depth97
+					goto l239
+				l240:
+					position, tokenIndex, depth = position239, tokenIndex239, depth239
 					if c := buffer[position]; c < 'A' || c > 'Z' {
-						goto l99
+						goto l241
 					}
 					position++
-					goto l97
-				l99:
-					position, tokenIndex, depth = position97, tokenIndex97, depth97
+					goto l239
+				l241:
+					position, tokenIndex, depth = position239, tokenIndex239, depth239
 					if c := buffer[position]; c < '0' || c > '9' {
-						goto l100
+						goto l242
 					}
 					position++
-					goto l97
-				l100:
-					position, tokenIndex, depth = position97, tokenIndex97, depth97
+					goto l239
+				l242:
+					position, tokenIndex, depth = position239, tokenIndex239, depth239
 					if buffer[position] != '_' {
-						goto l93
+						goto l234
 					}
 					position++
 				}
-			l97:
-			l95:
+			l239:
+			l237:
 				{
-					position96, tokenIndex96, depth96 := position, tokenIndex, depth
+					position238, tokenIndex238, depth238 := position, tokenIndex, depth
 					{
-						position101, tokenIndex101, depth101 := position, tokenIndex, depth
+						position243, tokenIndex243, depth243 := position, tokenIndex, depth
 						if c := buffer[position]; c < 'a' || c > 'z' {
-							goto l102
+							goto l244
 						}
 						position++
-						goto l101
-					l102:
-						position, tokenIndex, depth = position101, tokenIndex101, depth101
+						goto l243
+					l244:
+						position, tokenIndex, depth = position243, tokenIndex243, depth243
 						if c := buffer[position]; c < 'A' || c > 'Z' {
-							goto l103
+							goto l245
 						}
 						position++
-						goto l101
-					l103:
-						position, tokenIndex, depth = position101, tokenIndex101, depth101
+						goto l243
+					l245:
+						position, tokenIndex, depth = position243, tokenIndex243, depth243
 						if c := buffer[position]; c < '0' || c > '9' {
-							goto l104
+							goto l246
 						}
 						position++
-						goto l101
-					l104:
-						position, tokenIndex, depth = position101, tokenIndex101, depth101
+						goto l243
+					l246:
+						position, tokenIndex, depth = position243, tokenIndex243, depth243
 						if buffer[position] != '_' {
-							goto l96
+							goto l238
 						}
 						position++
 					}
-				l101:
-					goto l95
-				l96:
-					position, tokenIndex, depth = position96, tokenIndex96, depth96
+				l243:
+					goto l237
+				l238:
+					position, tokenIndex, depth = position238, tokenIndex238, depth238
 				}
-			l105:
+			l247:
 				{
-					position106, tokenIndex106, depth106 := position, tokenIndex, depth
+					position248, tokenIndex248, depth248 := position, tokenIndex, depth
 					if buffer[position] != '.' {
-						goto l106
+						goto l248
 					}
 					position++
 					{
-						position109, tokenIndex109, depth109 := position, tokenIndex, depth
+						position251, tokenIndex251, depth251 := position, tokenIndex, depth
 						if c := buffer[position]; c < 'a' || c > 'z' {
-							goto l110
+							goto l252
 						}
 						position++
-						goto l109
-					l110:
-						position, tokenIndex, depth = position109, tokenIndex109, depth109
+						goto l251
+					l252:
+						position, tokenIndex, depth = position251, tokenIndex251, depth251
 						if c := buffer[position]; c < 'A' || c > 'Z' {
-							goto l111
+							goto l253
 						}
 						position++
-						goto l109
-					l111:
-						position, tokenIndex, depth = position109, tokenIndex109, depth109
+						goto l251
+					l253:
+						position, tokenIndex, depth = position251, tokenIndex251, depth251
 						if c := buffer[position]; c < '0' || c > '9' {
-							goto l112
+							goto l254
 						}
 						position++
-						goto l109
-					l112:
-						position, tokenIndex, depth = position109, tokenIndex109, depth109
+						goto l251
+					l254:
+						position, tokenIndex, depth = position251, tokenIndex251, depth251
 						if buffer[position] != '_' {
-							goto l106
+							goto l248
 						}
 						position++
 					}
-				l109:
-				l107:
+				l251:
+				l249:
 					{
-						position108, tokenIndex108, depth108 := position, tokenIndex, depth
+						position250, tokenIndex250, depth250 := position, tokenIndex, depth
 						{
-							position113, tokenIndex113, depth113 := position, tokenIndex, depth
+							position255, tokenIndex255, depth255 := position, tokenIndex, depth
 							if c := buffer[position]; c < 'a' || c > 'z' {
-								goto l114
+								goto l256
 							}
 							position++
-							goto l113
-						l114:
-							position, tokenIndex, depth = position113, tokenIndex113, depth113
+							goto l255
+						l256:
+							position, tokenIndex, depth = position255, tokenIndex255, depth255
 							if c := buffer[position]; c < 'A' || c > 'Z' {
-								goto l115
+								goto l257
 							}
 							position++
-							goto l113
-						l115:
-							position, tokenIndex, depth = position113, tokenIndex113, depth113
+							goto l255
+						l257:
+							position, tokenIndex, depth = position255, tokenIndex255, depth255
 							if c := buffer[position]; c < '0' || c > '9' {
-								goto l116
+								goto l258
 							}
 							position++
-							goto l113
-						l116:
-							position, tokenIndex, depth = position113, tokenIndex113, depth113
+							goto l255
+						l258:
+							position, tokenIndex, depth = position255, tokenIndex255, depth255
 							if buffer[position] != '_' {
-								goto l108
+								goto l250
 							}
 							position++
 						}
-					l113:
-						goto l107
-					l108:
-						position, tokenIndex, depth = position108, tokenIndex108, depth108
+					l255:
+						goto l249
+					l250:
+						position, tokenIndex, depth = position250, tokenIndex250, depth250
 					}
-					goto l105
-				l106:
-					position, tokenIndex, depth = position106, tokenIndex106, depth106
+					goto l247
+				l248:
+					position, tokenIndex, depth = position248, tokenIndex248, depth248
 				}
 				depth--
-				add(RuleReference, position94)
+				add(RuleReference, position235)
 			}
 			return true
-		l93:
-			position, tokenIndex, depth = position93, tokenIndex93, depth93
+		l234:
+			position, tokenIndex, depth = position234, tokenIndex234, depth234
 			return false
 		},
-		/* 22 ws <- <(' ' / '\t' / '\n' / '\r')*> */
+		/* 34 ws <- <(' ' / '\t' / '\n' / '\r')*> */
 		func() bool {
 			{
-				position118 := position
+				position260 := position
 				depth++
-			l119:
+			l261:
 				{
-					position120, tokenIndex120, depth120 := position, tokenIndex, depth
+					position262, tokenIndex262, depth262 := position, tokenIndex, depth
 					{
-						position121, tokenIndex121, depth121 := position, tokenIndex, depth
+						position263, tokenIndex263, depth263 := position, tokenIndex, depth
 						if buffer[position] != ' ' {
-							goto l122
+							goto l264
 						}
 						position++
-						goto l121
-					l122:
-						position, tokenIndex, depth = position121, tokenIndex121, depth121
+						goto l263
+					l264:
+						position, tokenIndex, depth = position263, tokenIndex263, depth263
 						if buffer[position] != '\t' {
-							goto l123
+							goto l265
 						}
 						position++
-						goto l121
-					l123:
-						position, tokenIndex, depth = position121, tokenIndex121, depth121
+						goto l263
+					l265:
+						position, tokenIndex, depth = position263, tokenIndex263, depth263
 						if buffer[position] != '\n' {
-							goto l124
+							goto l266
 						}
 						position++
-						goto l121
-					l124:
-						position, tokenIndex, depth = position121, tokenIndex121, depth121
+						goto l263
+					l266:
+						position, tokenIndex, depth = position263, tokenIndex263, depth263
 						if buffer[position] != '\r' {
-							goto l120
+							goto l262
 						}
 						position++
 					}
-				l121:
-					goto l119
-				l120:
-					position, tokenIndex, depth = position120, tokenIndex120, depth120
+				l263:
+					goto l261
+				l262:
+					position, tokenIndex, depth = position262, tokenIndex262, depth262
 				}
 				depth--
-				add(Rulews, position118)
+				add(Rulews, position260)
 			}
 			return true
 		},
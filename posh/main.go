@@ -5,56 +5,249 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"launchpad.net/goyaml"
 
 	"github.com/vito/posh"
 )
 
+type pathsFlag []string
+
+func (f *pathsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *pathsFlag) Set(path string) error {
+	*f = append(*f, path)
+	return nil
+}
+
 var templateFile = flag.String("template", "", "path to manifest template")
-var stubFile = flag.String("stub", "", "path to stub .yml file")
+var stubFiles pathsFlag
+var overrideFiles pathsFlag
+var envNames pathsFlag
+
+var provenanceFile = flag.String("provenance", "", "path to write the origin of every value of the manifest to")
+var scopeLookup = flag.String("scope", "", "print the scopes searched for a name at a path, as path:name")
+
+func init() {
+	flag.Var(&stubFiles, "stub", "path to stub .yml file (repeatable; later stubs take precedence)")
+	flag.Var(&overrideFiles, "override", "path to .yml file whose values replace the template's (repeatable)")
+	flag.Var(&envNames, "env", "environment variable that env(\"NAME\") may read (repeatable)")
+}
 
 func main() {
-	flag.Parse()
+	// posh explain [flags] path prints how the value at path was derived
+	// instead of rendering the manifest
+	explaining := len(os.Args) > 1 && os.Args[1] == "explain"
+
+	if explaining {
+		flag.CommandLine.Parse(os.Args[2:])
+
+		if flag.NArg() != 1 {
+			log.Fatalln("usage: posh explain [flags] path")
+		}
+	} else {
+		flag.Parse()
+	}
 
-	var templateYAML, stubYAML interface{}
+	positions := posh.Positions{}
+	layout := posh.Layout{}
 
-	templateFile, err := ioutil.ReadFile(*templateFile)
-	if err != nil {
-		log.Fatalln("error reading template:", err)
+	aliases, flowed := posh.ExtractAliases(loadTemplate(*templateFile, map[string]bool{}, positions, layout))
+	prunes, flowed := posh.ExtractPrunes(flowed)
+
+	spice := &posh.Spice{Aliases: aliases, Env: envNames, Dir: filepath.Dir(*templateFile)}
+
+	for _, overridePath := range overrideFiles {
+		override, _, _ := loadYAML(overridePath, "override")
+
+		spice.Overrides = append(spice.Overrides, override)
 	}
 
-	stubFile, err := ioutil.ReadFile(*stubFile)
-	if err != nil {
-		log.Fatalln("error reading stub:", err)
+	stubPositions := []posh.Positions{}
+
+	for _, stubPath := range stubFiles {
+		stub, stubPositioned, _ := loadYAML(stubPath, "stub")
+
+		spice.Stubs = append(spice.Stubs, stub)
+		stubPositions = append(stubPositions, stubPositioned)
 	}
 
-	err = goyaml.Unmarshal(templateFile, &templateYAML)
-	if err != nil {
-		log.Fatalln("error parsing template:", err)
+	flowed, err := spice.Evaluate(flowed)
+
+	positions.Add(nil, spice.Positions)
+	layout.Add(nil, spice.Layout)
+
+	for _, warning := range spice.Warnings {
+		log.Println("warning:", warning)
 	}
 
-	err = goyaml.Unmarshal(stubFile, &stubYAML)
 	if err != nil {
-		log.Fatalln("error parsing stub:", err)
+		log.Fatalln(positions.Locate(err))
+	}
+
+	if *scopeLookup != "" {
+		printScopes(flowed, *scopeLookup, spice.Entries)
 	}
 
-	spice := &posh.Spice{Stub: posh.Sanitize(stubYAML)}
+	if explaining {
+		derivation, err := spice.Explain(flowed, strings.Split(flag.Arg(0), "."))
+		if err != nil {
+			log.Fatalln("explain:", err)
+		}
 
-	flowed := posh.Sanitize(templateYAML)
+		derivation.Locate(positions, stubPositions)
 
-	for didFlow := true; didFlow; flowed, didFlow = spice.Flow(flowed) {
+		fmt.Print(derivation)
+
+		return
+	}
+
+	for _, path := range spice.Overridden {
+		log.Println("overridden:", path)
 	}
 
-	err = posh.CheckResolved(flowed)
+	for _, path := range spice.Deleted {
+		log.Println("deleted:", path)
+	}
+
+	err = posh.CheckDeleted(flowed, spice.Deleted, spice.Entries)
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	rendered, err := goyaml.Marshal(flowed)
+	err = posh.CheckResolved(flowed, spice.Stubs, spice.Entries)
+	if err != nil {
+		log.Fatalln(positions.Locate(err))
+	}
+
+	evaluated := flowed
+
+	flowed = posh.Prune(flowed, prunes)
+
+	rendered, err := posh.Render(flowed, layout, spice.Entries)
 	if err != nil {
 		log.Fatalln("failed to render manifest:", err)
 	}
 
+	if *provenanceFile != "" {
+		provenance := spice.Provenance(evaluated, flowed, positions, stubPositions)
+
+		writeProvenance(*provenanceFile, provenance)
+	}
+
 	fmt.Printf("%s", rendered)
 }
+
+// writeProvenance writes the origin of each value as a YAML document keyed
+// by path, naming the stub file and position it came from
+func writeProvenance(path string, provenance map[string]posh.Provenance) {
+	doc := map[string]map[string]string{}
+
+	for key, origin := range provenance {
+		entry := map[string]string{"origin": origin.Origin}
+
+		if origin.Stub > 0 {
+			entry["stub"] = stubFiles[origin.Stub-1]
+		}
+
+		if origin.Position.File != "" {
+			entry["at"] = origin.Position.String()
+		}
+
+		doc[key] = entry
+	}
+
+	written, err := goyaml.Marshal(doc)
+	if err != nil {
+		log.Fatalln("failed to render provenance:", err)
+	}
+
+	err = ioutil.WriteFile(path, written, 0644)
+	if err != nil {
+		log.Fatalln("failed to write provenance:", err)
+	}
+}
+
+// printScopes prints the scope chain for a "path:name" lookup, nearest
+// scope first
+func printScopes(root posh.Node, lookup string, entries posh.Entries) {
+	split := strings.LastIndex(lookup, ":")
+	if split < 0 {
+		log.Fatalln("-scope must be given as path:name, not", lookup)
+	}
+
+	path, name := lookup[:split], lookup[split+1:]
+
+	scopes, err := posh.Scopes(root, strings.Split(path, "."), entries)
+	if err != nil {
+		log.Fatalln("scope:", err)
+	}
+
+	log.Printf("scopes searched for %s at %s:\n", name, path)
+
+	for _, line := range posh.Lookup(name, scopes) {
+		log.Println("  " + line)
+	}
+}
+
+// loadTemplate loads a template merged over the base templates it extends,
+// which are found relative to it, recording where each of its values came
+// from and how they were laid out
+func loadTemplate(path string, seen map[string]bool, positions posh.Positions, layout posh.Layout) posh.Node {
+	path = filepath.Clean(path)
+
+	if seen[path] {
+		log.Fatalln("template extends itself:", path)
+	}
+
+	seen[path] = true
+
+	loaded, located, laidOut := loadYAML(path, "template")
+
+	positions.Add(nil, located)
+
+	base, template := posh.ExtractBase(posh.Within(loaded, path))
+	if base == "" {
+		layout.Add(nil, laidOut)
+		return template
+	}
+
+	if !filepath.IsAbs(base) {
+		base = filepath.Join(filepath.Dir(path), base)
+	}
+
+	extended := posh.Extend(loadTemplate(base, seen, positions, layout), template)
+
+	// the base's keys keep their order, followed by any the template adds
+	layout.Add(nil, laidOut)
+
+	return extended
+}
+
+func loadYAML(path string, kind string) (posh.Node, posh.Positions, posh.Layout) {
+	var parsed interface{}
+
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Fatalln("error reading "+kind+":", err)
+	}
+
+	err = goyaml.Unmarshal(source, &parsed)
+	if err != nil {
+		log.Fatalln("error parsing "+kind+":", err)
+	}
+
+	sanitized, err := posh.Sanitize(parsed)
+	if err != nil {
+		log.Fatalln("error parsing "+kind+":", err)
+	}
+
+	located, laidOut := posh.Locate(source, path)
+
+	return sanitized, located, laidOut
+}
@@ -0,0 +1,674 @@
+package posh
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// the identity of an unnamed loop instance: the loop's, then its own
+var loopInstance *regexp.Regexp = regexp.MustCompile(`^([0-9]+)_[0-9]+$`)
+
+// Position is a place in a YAML file.
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// Positions is a side table of where each key and scalar of a template came
+// from, keyed by its dotted path. Named list entries are addressed by name,
+// the same way references address them, and any others by their index.
+type Positions map[string]Position
+
+// Find returns the position of a path, or of its nearest ancestor that has
+// one. Paths that only exist once a template has flowed, such as those of
+// named loop instances, are found by their ancestors. An unnamed loop
+// instance, e.g. jobs.2_0, is found in the body of its loop.
+func (positions Positions) Find(path []string) (Position, bool) {
+	path = positions.inLoops(path)
+
+	for i := len(path); i >= 0; i-- {
+		pos, found := positions[strings.Join(path[:i], ".")]
+		if found {
+			return pos, true
+		}
+	}
+
+	return Position{}, false
+}
+
+// inLoops follows the steps of a path through unnamed loop instances to the
+// bodies of their loops, e.g. jobs.2_0.x to jobs.2.(( for ... )).x
+func (positions Positions) inLoops(path []string) []string {
+	followed := []string{}
+
+	for _, step := range path {
+		sub := loopInstance.FindStringSubmatch(step)
+		if sub == nil {
+			followed = append(followed, step)
+			continue
+		}
+
+		followed = append(followed, sub[1])
+
+		loop := strings.Join(followed, ".") + "."
+
+		for key := range positions {
+			if !strings.HasPrefix(key, loop+"((") {
+				continue
+			}
+
+			end := strings.Index(key[len(loop):], "))")
+			if end >= 0 {
+				followed = append(followed, key[len(loop):len(loop)+end+2])
+				break
+			}
+		}
+	}
+
+	return followed
+}
+
+// Add records the positions of another table under a path, without
+// replacing any positions already known.
+func (positions Positions) Add(path []string, other Positions) {
+	for key, pos := range other {
+		full := strings.Join(path, ".")
+		if key != "" {
+			if full != "" {
+				full += "."
+			}
+
+			full += key
+		}
+
+		_, known := positions[full]
+		if !known {
+			positions[full] = pos
+		}
+	}
+}
+
+// Locate annotates parse errors, failed expressions and unresolved
+// expressions with their positions. Any other error is returned as is.
+func (positions Positions) Locate(err error) error {
+	switch err.(type) {
+	case *ParseError:
+		parseErr := err.(*ParseError)
+
+		pos, found := positions.Find(parseErr.Path)
+		if !found {
+			return err
+		}
+
+		if parseErr.Line == 1 {
+			pos.Column += parseErr.offset + parseErr.Column - 1
+		} else {
+			pos.Line += parseErr.Line - 1
+			pos.Column = parseErr.Column
+		}
+
+		parseErr.Position = pos
+
+	case *EvaluationError:
+		evalErr := err.(*EvaluationError)
+
+		pos, found := positions.Find(evalErr.Path)
+		if found {
+			evalErr.Position = pos
+		}
+
+	case *UnresolvedError:
+		nodes := err.(*UnresolvedError).Nodes
+
+		for i, node := range nodes {
+			pos, found := positions.Find(strings.Split(node.Path, "."))
+			if found {
+				nodes[i].Position = pos
+			}
+		}
+	}
+
+	return err
+}
+
+// Locate finds the position of every key and scalar in a YAML document,
+// along with its layout: the order of the keys, and the comments above
+// them and after their values. Block mappings and sequences are followed by
+// their indentation, and flow collections by their brackets; block scalars
+// are positioned as a whole.
+func Locate(source []byte, file string) (Positions, Layout) {
+	root := &locatedEntry{positions: Positions{}, layout: Layout{}}
+
+	lines := strings.Split(string(source), "\n")
+
+	stack := []*locatedFrame{}
+	blockIndent := -1
+
+	// the last line of a flow collection that spans several
+	flowEnd := -1
+
+	// keys and entries are ordered as they appear
+	order := 0
+
+	// the entries of a document that is itself a list
+	rootItems := 0
+
+	// comment lines waiting for the key they are written above
+	comments := []string{}
+
+	for i, line := range lines {
+		number := i + 1
+
+		if i <= flowEnd {
+			continue
+		}
+
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+
+		if blockIndent >= 0 {
+			if content == "" || indent > blockIndent {
+				continue
+			}
+
+			blockIndent = -1
+		}
+
+		if content != "" && content[0] == '#' {
+			comments = append(comments, strings.TrimRight(content, " \t"))
+			continue
+		}
+
+		if content == "" || strings.HasPrefix(content, "---") {
+			continue
+		}
+
+		isItem := content == "-" || strings.HasPrefix(content, "- ")
+
+		for len(stack) > 0 {
+			top := stack[len(stack)-1]
+
+			if top.indent < indent || (top.indent == indent && isItem && !top.isEntry) {
+				break
+			}
+
+			stack = stack[:len(stack)-1]
+
+			if top.isEntry {
+				top.entry.close()
+			}
+		}
+
+		entry, prefix, items := root, []string{}, &rootItems
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			entry, prefix, items = top.entry, top.path, &top.items
+		}
+
+		if isItem {
+			item := strings.TrimLeft(strings.TrimPrefix(content, "-"), " ")
+			itemIndent := len(line) - len(item)
+
+			order++
+
+			listed := &locatedEntry{
+				parent:    entry,
+				prefix:    prefix,
+				index:     *items,
+				start:     Position{file, number, itemIndent + 1},
+				positions: Positions{},
+				layout:    Layout{"": {Order: order, HeadComment: comments}},
+			}
+
+			comments = []string{}
+
+			*items++
+
+			stack = append(stack, &locatedFrame{indent: indent, entry: listed, isEntry: true})
+
+			if item == "" {
+				continue
+			}
+
+			if isFlow(item) {
+				flow, ok := scanFlow(lines, i, itemIndent, file)
+				if ok {
+					listed.locateFlow([]string{}, flow, &order)
+					listed.name = flow.name()
+					flowEnd = flow.end
+				}
+
+				continue
+			}
+
+			entry, prefix, content, indent = listed, []string{}, item, itemIndent
+
+			_, _, _, ok := splitKey(content)
+			if !ok {
+				listed.start.Column += quoteOffset(content)
+				continue
+			}
+		}
+
+		key, value, valueAt, ok := splitKey(content)
+		if !ok {
+			continue
+		}
+
+		path := childPath(prefix, key)
+
+		pos := Position{file, number, indent + 1}
+		if value != "" && value[0] != '#' {
+			pos.Column = indent + valueAt + 1 + quoteOffset(value)
+
+			if value[0] == '|' || value[0] == '>' {
+				blockIndent = indent
+			}
+		}
+
+		entry.positions[strings.Join(path, ".")] = pos
+
+		order++
+
+		entry.layout[strings.Join(path, ".")] = Placement{
+			Order:       order,
+			HeadComment: comments,
+			LineComment: lineComment(value),
+		}
+
+		comments = []string{}
+
+		if isFlow(value) {
+			flow, ok := scanFlow(lines, i, indent+valueAt, file)
+			if ok {
+				entry.locateFlow(path, flow, &order)
+				flowEnd = flow.end
+			}
+		}
+
+		if len(path) == 1 && key == "name" && entry.parent != nil {
+			name := strings.Trim(strings.TrimSpace(strings.TrimSuffix(value, lineComment(value))), `"'`)
+			if pathName.MatchString(name) {
+				entry.name = name
+			}
+		}
+
+		stack = append(stack, &locatedFrame{indent: indent, path: path, entry: entry})
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].isEntry {
+			stack[i].entry.close()
+		}
+	}
+
+	return root.positions, root.layout
+}
+
+// locatedFrame is a key or list entry whose children are more indented
+type locatedFrame struct {
+	indent  int
+	path    []string
+	entry   *locatedEntry
+	isEntry bool
+
+	// the list entries under a key so far
+	items int
+}
+
+// locatedEntry collects positions relative to a list entry until its name
+// is known
+type locatedEntry struct {
+	parent *locatedEntry
+	prefix []string
+	index  int
+	name   string
+
+	start     Position
+	positions Positions
+	layout    Layout
+}
+
+func (entry *locatedEntry) close() {
+	path := childPath(entry.prefix, strconv.Itoa(entry.index))
+	if entry.name != "" {
+		path = childPath(entry.prefix, entry.name)
+	}
+
+	entry.parent.positions.Add(path, Positions{"": entry.start})
+	entry.parent.positions.Add(path, entry.positions)
+	entry.parent.layout.Add(path, entry.layout)
+}
+
+// locateFlow records the keys and entries of a flow collection under a path
+// relative to the entry
+func (entry *locatedEntry) locateFlow(path []string, flow *flowValue, order *int) {
+	for i, key := range flow.keys {
+		entry.locateFlowValue(childPath(path, key), flow.values[i], order)
+	}
+
+	for i, item := range flow.items {
+		itemPath := childPath(path, strconv.Itoa(i))
+		if item.name() != "" {
+			itemPath = childPath(path, item.name())
+		}
+
+		entry.locateFlowValue(itemPath, item, order)
+	}
+}
+
+func (entry *locatedEntry) locateFlowValue(path []string, flow *flowValue, order *int) {
+	*order++
+
+	entry.positions[strings.Join(path, ".")] = flow.start
+	entry.layout[strings.Join(path, ".")] = Placement{Order: *order}
+
+	entry.locateFlow(path, flow, order)
+}
+
+// flowValue is a value within a flow collection, e.g. {a: 1, b: [2, 3]}
+type flowValue struct {
+	start Position
+
+	// the last line of the value, counting from 0
+	end int
+
+	keys   []string
+	values []*flowValue
+	items  []*flowValue
+	scalar string
+}
+
+// name is the value's name, if it is a map that can be addressed by one
+func (flow *flowValue) name() string {
+	for i, key := range flow.keys {
+		if key == "name" && pathName.MatchString(flow.values[i].scalar) {
+			return flow.values[i].scalar
+		}
+	}
+
+	return ""
+}
+
+func isFlow(value string) bool {
+	return value != "" && (value[0] == '{' || value[0] == '[')
+}
+
+// scanFlow scans the flow collection starting at a column of a line, which
+// may continue onto the lines after it
+func scanFlow(lines []string, line int, column int, file string) (*flowValue, bool) {
+	scanner := &flowScanner{lines: lines, line: line, column: column, file: file}
+
+	flow, ok := scanner.value()
+	if !ok {
+		return nil, false
+	}
+
+	flow.end = scanner.line
+
+	return flow, true
+}
+
+type flowScanner struct {
+	lines  []string
+	line   int
+	column int
+	file   string
+}
+
+func (scanner *flowScanner) peek() byte {
+	if scanner.line >= len(scanner.lines) || scanner.column >= len(scanner.lines[scanner.line]) {
+		return 0
+	}
+
+	return scanner.lines[scanner.line][scanner.column]
+}
+
+// space skips whitespace, comments, and the ends of lines
+func (scanner *flowScanner) space() {
+	for scanner.line < len(scanner.lines) {
+		text := scanner.lines[scanner.line]
+
+		switch {
+		case scanner.column >= len(text) || (text[scanner.column] == '#' && (scanner.column == 0 || text[scanner.column-1] == ' ')):
+			scanner.line++
+			scanner.column = 0
+
+		case text[scanner.column] == ' ' || text[scanner.column] == '\t':
+			scanner.column++
+
+		default:
+			return
+		}
+	}
+}
+
+func (scanner *flowScanner) value() (*flowValue, bool) {
+	scanner.space()
+
+	flow := &flowValue{start: Position{scanner.file, scanner.line + 1, scanner.column + 1}}
+
+	switch scanner.peek() {
+	case '{':
+		scanner.column++
+
+		return flow, scanner.collection('}', func() bool {
+			key, ok := scanner.scalar()
+			if !ok {
+				return false
+			}
+
+			scanner.space()
+
+			if scanner.peek() != ':' {
+				return false
+			}
+
+			scanner.column++
+			scanner.space()
+
+			val := &flowValue{start: Position{scanner.file, scanner.line + 1, scanner.column + 1}}
+
+			if scanner.peek() != ',' && scanner.peek() != '}' {
+				val, ok = scanner.value()
+				if !ok {
+					return false
+				}
+			}
+
+			flow.keys = append(flow.keys, key.scalar)
+			flow.values = append(flow.values, val)
+
+			return true
+		})
+
+	case '[':
+		scanner.column++
+
+		return flow, scanner.collection(']', func() bool {
+			item, ok := scanner.value()
+			if !ok {
+				return false
+			}
+
+			flow.items = append(flow.items, item)
+
+			return true
+		})
+	}
+
+	return scanner.scalar()
+}
+
+// collection scans the comma-separated elements of a map or list up to its
+// closing bracket
+func (scanner *flowScanner) collection(closing byte, element func() bool) bool {
+	for {
+		scanner.space()
+
+		switch scanner.peek() {
+		case closing:
+			scanner.column++
+			return true
+
+		case 0:
+			return false
+		}
+
+		if !element() {
+			return false
+		}
+
+		scanner.space()
+
+		switch scanner.peek() {
+		case ',':
+			scanner.column++
+		case closing:
+		default:
+			return false
+		}
+	}
+}
+
+// scalar scans a plain or quoted scalar, which ends at the end of its line
+// unless it is quoted
+func (scanner *flowScanner) scalar() (*flowValue, bool) {
+	scanner.space()
+
+	if scanner.line >= len(scanner.lines) {
+		return nil, false
+	}
+
+	text := scanner.lines[scanner.line]
+	start := scanner.column
+
+	flow := &flowValue{start: Position{scanner.file, scanner.line + 1, start + 1}}
+
+	quote := scanner.peek()
+	if quote == '"' || quote == '\'' {
+		flow.start.Column++
+
+		end := start + 1
+		for end < len(text) && text[end] != quote {
+			if text[end] == '\\' && quote == '"' {
+				end++
+			}
+
+			end++
+		}
+
+		if end >= len(text) {
+			return nil, false
+		}
+
+		flow.scalar = text[start+1 : end]
+		scanner.column = end + 1
+
+		return flow, true
+	}
+
+	end := start
+	for end < len(text) {
+		c := text[end]
+
+		if strings.IndexByte(",[]{}", c) >= 0 || (c == '#' && end > start && text[end-1] == ' ') {
+			break
+		}
+
+		if c == ':' && (end+1 == len(text) || strings.IndexByte(" ,]}", text[end+1]) >= 0) {
+			break
+		}
+
+		end++
+	}
+
+	flow.scalar = strings.TrimRight(text[start:end], " \t")
+	scanner.column = end
+
+	return flow, true
+}
+
+// splitKey splits a "key: value" line, returning where the value starts
+func splitKey(content string) (string, string, int, bool) {
+	key, rest := "", ""
+
+	if content[0] == '"' || content[0] == '\'' {
+		end := 1
+		for end < len(content) && content[end] != content[0] {
+			if content[end] == '\\' && content[0] == '"' {
+				end++
+			}
+
+			end++
+		}
+
+		if end >= len(content) {
+			return "", "", 0, false
+		}
+
+		key = strings.Replace(content[1:end], `\"`, `"`, -1)
+		rest = content[end+1:]
+	} else {
+		colon := strings.Index(content, ": ")
+		if colon < 0 {
+			if !strings.HasSuffix(content, ":") {
+				return "", "", 0, false
+			}
+
+			colon = len(content) - 1
+		}
+
+		key = content[:colon]
+		rest = content[colon:]
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return "", "", 0, false
+	}
+
+	value := strings.TrimLeft(rest[1:], " ")
+
+	return key, value, len(content) - len(value), true
+}
+
+// lineComment finds the comment after a value, outside of any quoted
+// scalars
+func lineComment(value string) string {
+	var quote byte
+
+	for i := 0; i < len(value); i++ {
+		switch {
+		case quote != 0:
+			if value[i] == '\\' && quote == '"' {
+				i++
+			} else if value[i] == quote {
+				quote = 0
+			}
+
+		case (value[i] == '"' || value[i] == '\'') && (i == 0 || strings.IndexByte(" [{,", value[i-1]) >= 0):
+			quote = value[i]
+
+		case value[i] == '#' && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t'):
+			return strings.TrimRight(value[i:], " \t")
+		}
+	}
+
+	return ""
+}
+
+// quoteOffset skips the opening quote of a quoted scalar
+func quoteOffset(value string) int {
+	if value != "" && (value[0] == '"' || value[0] == '\'') {
+		return 1
+	}
+
+	return 0
+}
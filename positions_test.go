@@ -0,0 +1,150 @@
+package posh
+
+import (
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	source := `name: deployment
+things:
+- b: 1
+  c: two
+- b: 3
+  c: four
+jobs:
+  - name: router
+    instances: 2
+  -
+    name: collector
+    instances: 1
+properties:
+  cc:
+    srv_api_uri: "http://api"
+    description: |
+      not: a key
+    after: 1
+`
+
+	positions, _ := Locate([]byte(source), "pos.yml")
+
+	expected := map[string]Position{
+		"name":                      {"pos.yml", 1, 7},
+		"things":                    {"pos.yml", 2, 1},
+		"things.0":                  {"pos.yml", 3, 3},
+		"things.0.b":                {"pos.yml", 3, 6},
+		"things.0.c":                {"pos.yml", 4, 6},
+		"things.1":                  {"pos.yml", 5, 3},
+		"things.1.b":                {"pos.yml", 5, 6},
+		"things.1.c":                {"pos.yml", 6, 6},
+		"jobs.router":               {"pos.yml", 8, 5},
+		"jobs.router.instances":     {"pos.yml", 9, 16},
+		"jobs.collector":            {"pos.yml", 10, 4},
+		"jobs.collector.instances":  {"pos.yml", 12, 16},
+		"properties.cc.srv_api_uri": {"pos.yml", 15, 19},
+		"properties.cc.description": {"pos.yml", 16, 18},
+		"properties.cc.after":       {"pos.yml", 18, 12},
+	}
+
+	for path, pos := range expected {
+		found, ok := positions[path]
+		if !ok {
+			t.Errorf("%s: not located", path)
+			continue
+		}
+
+		if found != pos {
+			t.Errorf("%s: expected %s, got %s", path, pos, found)
+		}
+	}
+
+	for _, path := range []string{"things.b", "jobs.0", "jobs.1", "properties.cc.description.not"} {
+		_, ok := positions[path]
+		if ok {
+			t.Errorf("%s: should not be located", path)
+		}
+	}
+}
+
+func TestLocateFlowCollections(t *testing.T) {
+	source := `jobs: [{name: router, instances: 2}, {instances: "3"}]
+inline: {a: 1,
+  b: [x, y]} # after
+after: 1
+`
+
+	positions, layout := Locate([]byte(source), "flow.yml")
+
+	expected := map[string]Position{
+		"jobs.router":           {"flow.yml", 1, 8},
+		"jobs.router.instances": {"flow.yml", 1, 34},
+		"jobs.1":                {"flow.yml", 1, 38},
+		"jobs.1.instances":      {"flow.yml", 1, 51},
+		"inline.a":              {"flow.yml", 2, 13},
+		"inline.b.1":            {"flow.yml", 3, 10},
+		"after":                 {"flow.yml", 4, 8},
+	}
+
+	for path, pos := range expected {
+		found := positions[path]
+		if found != pos {
+			t.Errorf("%s: expected %s, got %s", path, pos, found)
+		}
+	}
+
+	if layout["inline.a"].Order >= layout["inline.b"].Order {
+		t.Errorf("inline.a should be laid out before inline.b")
+	}
+
+	if layout["inline.b"].Order >= layout["after"].Order {
+		t.Errorf("inline.b should be laid out before after")
+	}
+}
+
+func TestLocateDocumentList(t *testing.T) {
+	positions, _ := Locate([]byte("- a: 1\n- a: 2\n"), "list.yml")
+
+	pos, found := positions.Find([]string{"1", "a"})
+	if !found || pos != (Position{"list.yml", 2, 6}) {
+		t.Errorf("expected list.yml:2:6, got %s", pos)
+	}
+}
+
+func TestFindAncestor(t *testing.T) {
+	positions := Positions{"jobs.router": {"pos.yml", 8, 5}}
+
+	pos, found := positions.Find([]string{"jobs", "router", "networks", "0"})
+	if !found || pos != (Position{"pos.yml", 8, 5}) {
+		t.Errorf("expected pos.yml:8:5, got %s", pos)
+	}
+}
+
+func TestLocateShiftedEntries(t *testing.T) {
+	source := `flag: false
+things:
+- (( if flag )):
+    b: 1
+- (( for x in [1, 2] )):
+    b: (( x ))
+- b: (( 1 + "x" ))
+`
+
+	positions, _ := Locate([]byte(source), "pos.yml")
+
+	spice := &Spice{}
+
+	_, err := spice.Evaluate(parse(t, source))
+
+	evalErr, ok := positions.Locate(err).(*EvaluationError)
+	if !ok {
+		t.Fatalf("expected an evaluation error, got %#v", err)
+	}
+
+	if evalErr.Position != (Position{"pos.yml", 7, 6}) {
+		t.Errorf("expected pos.yml:7:6, got %s", evalErr.Position)
+	}
+
+	pos, found := positions.Find([]string{"things", "1_1", "b"})
+	if !found || pos != (Position{"pos.yml", 6, 8}) {
+		t.Errorf("expected the loop body's pos.yml:6:8, got %s", pos)
+	}
+}
@@ -0,0 +1,127 @@
+package posh
+
+import (
+	"strings"
+)
+
+// Provenance is where a value of the rendered manifest came from.
+type Provenance struct {
+	// "template" for a literal of the template, "stub" for a value merged
+	// from a stub, "default" for the fallback of an || expression, "function"
+	// for the result of a function or (( auto )), "loop" for a loop variable,
+	// and "expression" for anything computed from several values
+	Origin string
+
+	// the stub (counting from 1) the value came from, or 0
+	Stub int
+
+	Position Position
+}
+
+// Provenance finds the origin of every leaf of a rendered manifest, keyed
+// by its path. The leaves are explained in the evaluated template, which
+// still has any values that the manifest prunes. Unnamed list entries are
+// keyed by their identity, like everywhere else, and an empty list or map
+// is a leaf of its own.
+func (s *Spice) Provenance(evaluated Node, rendered Node, template Positions, stubs []Positions) map[string]Provenance {
+	provenance := map[string]Provenance{}
+
+	eachLeaf(rendered, []string{}, s.Entries, func(path []string) {
+		derivation, err := s.Explain(evaluated, path)
+		if err != nil {
+			return
+		}
+
+		derivation.Locate(template, stubs)
+
+		kind, from := origin(derivation, derivation)
+
+		provenance[strings.Join(path, ".")] = Provenance{
+			Origin:   kind,
+			Stub:     from.Stub,
+			Position: from.Position,
+		}
+	})
+
+	return provenance
+}
+
+// origin follows a derivation to whatever decided its value, returning the
+// kind of origin and the nearest step with a path, which locates it
+func origin(d *Derivation, at *Derivation) (string, *Derivation) {
+	if d.Path != nil {
+		at = d
+	}
+
+	if d.Stub > 0 {
+		return "stub", d
+	}
+
+	switch d.expr.(type) {
+	case nil:
+		if len(d.Parts) == 0 {
+			return "template", at
+		}
+
+		return origin(d.Parts[0], at)
+
+	case *ReferenceExpr:
+		if len(d.Parts) == 0 {
+			return "loop", at
+		}
+
+		return origin(d.Parts[0], at)
+
+	case *MergeExpr:
+		if len(d.Parts) == 0 {
+			// deep merges combine every stub
+			return "stub", at
+		}
+
+		return origin(d.Parts[0], at)
+
+	case *OrExpr:
+		if d.Parts[0].Value != nil {
+			return origin(d.Parts[0], at)
+		}
+
+		return "default", at
+
+	case *CallExpr, *AutoExpr:
+		return "function", at
+
+	case *StringExpr, *IntegerExpr, *BooleanExpr:
+		return "template", at
+	}
+
+	return "expression", at
+}
+
+func eachLeaf(root Node, path []string, entries Entries, visit func(path []string)) {
+	switch root.(type) {
+	case map[string]Node:
+		attrs := root.(map[string]Node)
+
+		if len(attrs) == 0 {
+			visit(path)
+		}
+
+		for key, val := range attrs {
+			eachLeaf(val, childPath(path, key), entries, visit)
+		}
+
+	case []Node:
+		list := root.([]Node)
+
+		if len(list) == 0 {
+			visit(path)
+		}
+
+		for i, val := range list {
+			eachLeaf(val, entries.path(path, i, val, len(list)), entries, visit)
+		}
+
+	default:
+		visit(path)
+	}
+}
@@ -0,0 +1,94 @@
+package posh
+
+import (
+	"testing"
+)
+
+func TestProvenanceOfLiteralsAndListMerges(t *testing.T) {
+	template := `
+compilation:
+  workers: 6
+jobs:
+- name: router
+  instances: 1
+- (( merge ))
+`
+
+	stub := `
+compilation:
+  workers: 6
+jobs:
+- name: nats
+  instances: 2
+`
+
+	spice := &Spice{Stubs: []Node{parse(t, stub)}}
+
+	evaluated, err := spice.Evaluate(parse(t, template))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provenance := spice.Provenance(evaluated, evaluated, nil, nil)
+
+	expected := map[string]string{
+		// equal to the stub's value, but never merged from it
+		"compilation.workers":   "template",
+		"jobs.router.instances": "template",
+		"jobs.nats.instances":   "stub",
+	}
+
+	for path, origin := range expected {
+		if provenance[path].Origin != origin {
+			t.Errorf("%s: expected %s, got %s", path, origin, provenance[path].Origin)
+		}
+	}
+}
+
+func TestProvenanceOfUnnamedEntries(t *testing.T) {
+	template := `
+azs: [z1, z2]
+things:
+- (( for az in azs )):
+    zone: (( az ))
+    fixed: 1
+ips: (( merge ))
+counts: (( [1, 2] ))
+extra:
+- host: template
+- (( merge ))
+`
+
+	stub := `
+ips: [10.0.0.1]
+extra:
+- host: stub
+`
+
+	spice := &Spice{Stubs: []Node{parse(t, stub)}}
+
+	evaluated, err := spice.Evaluate(parse(t, template))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provenance := spice.Provenance(evaluated, evaluated, nil, nil)
+
+	expected := map[string]string{
+		"azs.1":            "template",
+		"things.0_1.zone":  "loop",
+		"things.0_1.fixed": "template",
+		"ips.0":            "stub",
+		"counts.1":         "expression",
+		"extra.0.host":     "template",
+
+		// numbered after all of the template's entries, the merge included
+		"extra.2.host": "stub",
+	}
+
+	for path, origin := range expected {
+		if provenance[path].Origin != origin {
+			t.Errorf("%s: expected %s, got %s", path, origin, provenance[path].Origin)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package posh
+
+import (
+	"strings"
+)
+
+// ExtractPrunes splits the list of paths to prune from the rest of a
+// template:
+//
+//	prune:
+//	  - ec2_constants
+//	  - properties.template_only
+func ExtractPrunes(root Node) ([]string, Node) {
+	prunes := []string{}
+
+	val, rest := extract(root, "prune")
+
+	declared, ok := val.([]Node)
+	if !ok {
+		return prunes, root
+	}
+
+	for _, path := range declared {
+		str, ok := path.(string)
+		if ok {
+			prunes = append(prunes, str)
+		}
+	}
+
+	return prunes, rest
+}
+
+// Prune removes the given paths from a flowed template. They are only
+// inputs for other expressions, and are not part of the rendered output.
+func Prune(root Node, prunes []string) Node {
+	for _, path := range prunes {
+		root = deleteInPath(strings.Split(path, "."), root)
+	}
+
+	return root
+}
@@ -1,12 +1,15 @@
 package posh
 
 import (
+	"errors"
 	"fmt"
 )
 
 type Node interface{}
 
-func Sanitize(root interface{}) Node {
+// Sanitize converts parsed YAML into Nodes. Only string keys are
+// supported.
+func Sanitize(root interface{}) (Node, error) {
 	switch root.(type) {
 	case map[interface{}]interface{}:
 		sanitized := map[string]Node{}
@@ -14,36 +17,46 @@ func Sanitize(root interface{}) Node {
 		for key, val := range root.(map[interface{}]interface{}) {
 			str, ok := key.(string)
 			if !ok {
-				panic("NO")
+				return nil, errors.New(fmt.Sprintf("non-string key: %#v", key))
 			}
 
-			sanitized[str] = Sanitize(val)
+			sub, err := Sanitize(val)
+			if err != nil {
+				return nil, err
+			}
+
+			sanitized[str] = sub
 		}
 
-		return Node(sanitized)
+		return Node(sanitized), nil
 
 	case []interface{}:
 		sanitized := []Node{}
 
 		for _, val := range root.([]interface{}) {
-			sanitized = append(sanitized, Sanitize(val))
+			sub, err := Sanitize(val)
+			if err != nil {
+				return nil, err
+			}
+
+			sanitized = append(sanitized, sub)
 		}
 
-		return Node(sanitized)
+		return Node(sanitized), nil
 
 	case string:
-		return Node(root.(string))
+		return Node(root.(string)), nil
 
 	case []byte:
-		return Node(string(root.([]byte)))
+		return Node(string(root.([]byte))), nil
 
 	case int:
-		return Node(root.(int))
+		return Node(root.(int)), nil
 
 	case bool:
-		return Node(root.(bool))
+		return Node(root.(bool)), nil
 
 	default:
-		panic(fmt.Sprintf("unknown type during sanitization: %#v\n", root))
+		return nil, errors.New(fmt.Sprintf("unknown type during sanitization: %#v", root))
 	}
 }
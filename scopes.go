@@ -0,0 +1,75 @@
+package posh
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Scope is a map that an expression can see, along with its path.
+type Scope struct {
+	Path []string
+	Map  map[string]Node
+}
+
+func (scope Scope) String() string {
+	if len(scope.Path) == 0 {
+		return "(root)"
+	}
+
+	return strings.Join(scope.Path, ".")
+}
+
+// Scopes returns the scope chain seen by the value at path, nearest first.
+// Named list entries are scopes like any other map, and unnamed ones are
+// addressed by their identity. Loops have already been expanded in a
+// flowed template, so their variables are not shown.
+func Scopes(root Node, path []string, entries Entries) ([]Scope, error) {
+	scopes := []Scope{}
+
+	here := root
+
+	for i, step := range path {
+		attrs, ok := unwrap(here).(map[string]Node)
+		if ok {
+			scopes = append([]Scope{{Path: path[:i], Map: attrs}}, scopes...)
+		}
+
+		var found bool
+
+		here, found = entries.step(path[:i], step, here)
+		if !found {
+			return nil, errors.New(fmt.Sprintf("%s is not defined", strings.Join(path[:i+1], ".")))
+		}
+	}
+
+	return scopes, nil
+}
+
+// Lookup explains how a name resolves in a scope chain: every scope that
+// defines it, the nearest of which wins.
+func Lookup(name string, scopes []Scope) []string {
+	lines := []string{}
+
+	resolved := false
+
+	for _, scope := range scopes {
+		_, defines := scope.Map[name]
+
+		switch {
+		case !defines:
+			lines = append(lines, scope.String())
+		case !resolved:
+			resolved = true
+			lines = append(lines, scope.String()+": "+name+" resolves here")
+		default:
+			lines = append(lines, scope.String()+": "+name+" is shadowed")
+		}
+	}
+
+	if !resolved {
+		lines = append(lines, name+" is not defined in any scope")
+	}
+
+	return lines
+}
@@ -3,20 +3,95 @@ package posh
 import (
 	"errors"
 	"fmt"
-	"log" // TODO: no
+	"os"
 	"regexp"
+	"strconv"
+	"strings"
 )
 
 var embeddedPosh *regexp.Regexp = regexp.MustCompile(`\(\(\s*(.*?)\s*\)\)`)
 var pathName *regexp.Regexp = regexp.MustCompile("^[a-zA-Z0-9_]+$")
 
+const environmentScope = "$env"
+
+// importDirectory is the directory that imports are relative to, which is
+// also kept in the environment scope
+const importDirectory = "$dir"
+
+// settledLevel is how far the template has settled, which is kept in the
+// environment scope for references to tell whether they are missing
+const settledLevel = "$settled"
+
+// Context is the chain of scopes an expression can see, outermost first:
+// the environment, then every map enclosing the expression, down to the one
+// that holds it. List entries are maps like any other, so a job's keys are
+// in scope for everything within the job. Names resolve in the nearest
+// scope that defines them.
 type Context []map[string]Node
 
 type Spice struct {
-	Stub Node
+	// for any given path, later stubs take precedence over earlier ones
+	Stubs []Node
+
+	// deprecated stub paths, keyed by the template path they moved to
+	Aliases map[string]string
+
+	// values that replace the template's at every path they set, whether or
+	// not the template marked it for merging; later overrides take precedence
+	Overrides []Node
+
+	// template paths removed by the stubs' (( delete )) markers
+	Deleted []string
+
+	// template paths replaced by the overrides
+	Overridden []string
+
+	// environment variables that env("NAME") may read; any others are unset
+	Env []string
+
+	// the directory that (( import )) paths are relative to, unless the
+	// import is in a template scoped by Within or in an imported file; the
+	// working directory if empty
+	Dir string
+
+	// the positions and layout of the imported files' contents, keyed by
+	// where they were imported
+	Positions Positions
+	Layout    Layout
+
+	// the identity of each list entry, as of the last flow
+	Entries Entries
+
+	// deprecated stub paths that were used, found on the first flow
+	Warnings []string
 
 	path    []string
 	context Context
+
+	prepared bool
+
+	// how far the template has settled, from unsettled to unresolvedMissing
+	settled int
+
+	// reference cycles found by the last resolve
+	cycles [][]*pendingNode
+
+	// the expression behind each resolved path, for Explain
+	derived map[string]*PoshNode
+
+	// the lists merged with the stubs' lists, for Explain
+	listMerged map[string]bool
+
+	// the paths marked by the stubs' (( delete )) markers, found on the first
+	// flow
+	deleting map[string]bool
+
+	// the values of the overrides by path, found on the first flow
+	overriding map[string]Node
+
+	// how many entries each list had in the template, after which the
+	// entries merged in from the stubs are numbered
+	listed map[string]int
 }
 
 type PoshNode struct {
@@ -24,49 +99,97 @@ type PoshNode struct {
 
 	Expression Expression
 
+	source  string
 	path    []string
 	context Context
 }
 
-func (s *Spice) Flow(root Node) (Node, bool) {
-	return s.flow(root, []string{}, []map[string]Node{})
+// ConditionalNode is a subtree that is only included in the enclosing map or
+// list if its condition is not false.
+type ConditionalNode struct {
+	Node
+
+	Condition Expression
+
+	source string
 }
 
-func CheckResolved(root Node) error {
-	switch root.(type) {
-	case map[string]Node:
-		for _, val := range root.(map[string]Node) {
-			err := CheckResolved(val)
-			if err != nil {
-				return err
-			}
-		}
+// LoopNode is a list entry that expands into a copy of its body for each
+// value in its list, with the value bound to its variable.
+type LoopNode struct {
+	Node
 
-	case []Node:
-		for _, val := range root.([]Node) {
-			err := CheckResolved(val)
-			if err != nil {
-				return err
-			}
+	Variable string
+	List     Expression
+	Bindings []Binding
+
+	source string
+}
+
+// KeyedNode is a map entry whose key is computed by an expression. It stays
+// under its source key until the expression can be resolved.
+type KeyedNode struct {
+	Node
+
+	Key      Expression
+	Bindings []Binding
+
+	source string
+}
+
+// DeletedNode is a map key that a stub deleted. It stays in place while the
+// template flows, so that a reference to the key stops there rather than
+// finding a key of the same name further out, and is removed once the
+// template has settled.
+type DeletedNode struct{}
+
+// TextNode is a string that is never compiled, such as the contents of a
+// file imported as text, in which anything that looks like an expression
+// is left as it is.
+type TextNode string
+
+// Flow runs one pass over a template, returning the flowed template and
+// whether anything changed.
+func (s *Spice) Flow(root Node) (Node, bool, error) {
+	if !s.prepared {
+		s.Stubs, s.Warnings = alias(s.Stubs, s.Aliases)
+		s.collectDeletions()
+		s.collectOverrides()
+
+		if s.Positions == nil {
+			s.Positions = Positions{}
 		}
 
-	case *PoshNode:
-		posh := root.(*PoshNode)
+		if s.Layout == nil {
+			s.Layout = Layout{}
+		}
 
-		return errors.New(fmt.Sprintf("could not resolve: %#v\n", posh.Expression))
+		if s.Entries == nil {
+			s.Entries = Entries{}
+		}
 
-	case string, int, bool:
+		s.listed = map[string]int{}
 
-	default:
-		return errors.New(fmt.Sprintf("unknown node type: %#v\n", root))
+		s.prepared = true
 	}
 
-	return nil
+	return s.flow(root, []string{}, Context{s.environment()})
 }
 
-func (s *Spice) flow(root Node, path []string, context Context) (Node, bool) {
+func (s *Spice) flow(root Node, path []string, context Context) (Node, bool, error) {
 	switch root.(type) {
 	case map[string]Node:
+		dir, ok := directive(root.(map[string]Node), path, context)
+		if ok {
+			return dir, true, nil
+		}
+
+		merged, ok := overlay(root.(map[string]Node))
+		if ok {
+			s.Entries.forget(path)
+			return merged, true, nil
+		}
+
 		return s.flowMap(root.(map[string]Node), path, context)
 
 	case []Node:
@@ -77,30 +200,107 @@ func (s *Spice) flow(root Node, path []string, context Context) (Node, bool) {
 
 	case *PoshNode:
 		posh := root.(*PoshNode)
-		evaluated := posh.Expression.Evaluate(context, s.Stub)
+
+		evaluated, err := posh.Expression.Evaluate(context, s.Stubs)
+		if missing(err) {
+			return posh, false, nil
+		}
+
+		if err != nil {
+			return nil, false, &EvaluationError{Path: path, Source: posh.source, Err: err}
+		}
 
 		if evaluated != nil {
+			s.derive(path, posh)
+
 			posh.Node = evaluated
-			return posh.Node, true
+			return posh.Node, true, nil
 		}
 
-		return posh, false
+		return posh, false, nil
+
+	case *ConditionalNode, *LoopNode, *KeyedNode:
+		// decided by the enclosing map or list
+		return root, false, nil
 
-	case int, bool:
-		return root, false
+	case *DeletedNode:
+		return root, false, nil
+
+	case int, bool, TextNode:
+		return root, false, nil
 
 	default:
-		panic(fmt.Sprintf("unknown node type during flow: %#v\n", root))
+		return nil, false, errors.New(fmt.Sprintf("%s: unknown node type %T", strings.Join(path, "."), root))
 	}
 }
 
-func (s *Spice) flowMap(root map[string]Node, path []string, context Context) (Node, bool) {
+func (s *Spice) flowMap(root map[string]Node, path []string, context Context) (Node, bool, error) {
 	newMap := make(map[string]Node)
 
 	didFlow := false
 
 	for key, val := range root {
-		flowedVal, didFlowVal := s.flow(val, append(path, key), append(context, root))
+		_, deleted := val.(*DeletedNode)
+		if deleted {
+			newMap[key] = val
+			continue
+		}
+
+		if s.deletes(childPath(path, key)) {
+			newMap[key] = &DeletedNode{}
+			didFlow = true
+			continue
+		}
+
+		val, overridden := s.override(childPath(path, key), val)
+		if overridden {
+			didFlow = true
+		}
+
+		val, include, decided, err := s.decide(val, childPath(path, key), append(context, root))
+		if err != nil {
+			return nil, false, err
+		}
+
+		if decided {
+			didFlow = true
+		}
+
+		if !include {
+			continue
+		}
+
+		key, val, resolved, rekeyed, err := s.rekey(key, val, path, append(context, root))
+		if err != nil {
+			return nil, false, err
+		}
+
+		if rekeyed {
+			didFlow = true
+
+			if s.deletes(childPath(path, key)) {
+				newMap[key] = &DeletedNode{}
+				continue
+			}
+
+			val, _ = s.override(childPath(path, key), val)
+		}
+
+		if !resolved {
+			newMap[key] = val
+			continue
+		}
+
+		_, collides := newMap[key]
+		if collides {
+			return nil, false, errors.New("duplicate key " + strings.Join(childPath(path, key), "."))
+		}
+
+		flowedVal, didFlowVal, err := s.flow(val, childPath(path, key), append(context, root))
+		if err != nil {
+			return nil, false, err
+		}
+
 		newMap[key] = flowedVal
 
 		if didFlowVal {
@@ -108,57 +308,615 @@ func (s *Spice) flowMap(root map[string]Node, path []string, context Context) (N
 		}
 	}
 
-	return Node(newMap), didFlow
+	return Node(newMap), didFlow, nil
 }
 
-func (s *Spice) flowList(root []Node, path []string, context Context) (Node, bool) {
+func (s *Spice) flowList(root []Node, path []string, context Context) (Node, bool, error) {
+	key := strings.Join(path, ".")
+
+	_, recorded := s.Entries[key]
+	if !recorded {
+		s.listed[key] = len(root)
+	}
+
+	ids := s.Entries.of(path, len(root))
+
+	merge, entries := listMerge(root, path, context)
+	if merge != nil && settled(entries, merge.key(), path, context) {
+		if s.listMerged == nil {
+			s.listMerged = map[string]bool{}
+		}
+
+		s.listMerged[key] = true
+
+		entryIDs := []string{}
+		for i, val := range root {
+			_, isMerge := listMergeEntry(val, path, context)
+			if !isMerge {
+				entryIDs = append(entryIDs, ids[i])
+			}
+		}
+
+		merged := merge.MergeList(entries, s.Stubs)
+
+		s.Entries[key] = mergedEntries(merge, entryIDs, len(merged), s.listed[key], s.Stubs)
+
+		return Node(merged), true, nil
+	}
+
 	newList := []Node{}
+	newIDs := []string{}
 
 	didFlow := false
 
-	for _, val := range root {
-		entryPath := path
+	for i, val := range root {
+		_, isMerge := listMergeEntry(val, path, context)
+		if isMerge {
+			// waits for the rest of the list to settle
+			newList = append(newList, val)
+			newIDs = append(newIDs, ids[i])
+			continue
+		}
 
-		switch val.(type) {
-		case map[string]Node:
-			nameNode := val.(map[string]Node)["name"]
+		valPath := identifiedPath(path, ids[i], val)
+
+		if s.deletes(valPath) {
+			didFlow = true
+			continue
+		}
 
-			name, ok := nameNode.(string)
-			if ok && pathName.MatchString(name) {
-				entryPath = append(path, name)
+		val, include, decided, err := s.decide(val, valPath, context)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if decided {
+			didFlow = true
+		}
+
+		if !include {
+			continue
+		}
+
+		loop, ok := val.(*LoopNode)
+		if ok {
+			expanded, instanceIDs, err := s.expand(loop, path, ids[i], context)
+			if err != nil {
+				return nil, false, err
 			}
+
+			if expanded == nil {
+				newList = append(newList, loop)
+				newIDs = append(newIDs, ids[i])
+				continue
+			}
+
+			didFlow = true
+
+			for j, instance := range expanded {
+				instancePath := identifiedPath(path, instanceIDs[j], instance)
+				if s.deletes(instancePath) {
+					continue
+				}
+
+				// flows at once so that nothing sees an instance before its
+				// overrides
+				flowedInstance, _, err := s.flow(instance, instancePath, context)
+				if err != nil {
+					return nil, false, err
+				}
+
+				newList = append(newList, flowedInstance)
+				newIDs = append(newIDs, instanceIDs[j])
+			}
+
+			continue
+		}
+
+		flowedVal, didFlowVal, err := s.flow(val, valPath, context)
+		if err != nil {
+			return nil, false, err
 		}
 
-		flowedVal, didFlowVal := s.flow(val, entryPath, context)
 		if didFlowVal {
 			didFlow = true
 		}
 
 		newList = append(newList, flowedVal)
+		newIDs = append(newIDs, ids[i])
+	}
+
+	s.Entries[key] = newIDs
+
+	return Node(newList), didFlow, nil
+}
+
+func (s *Spice) flowScalar(root string, path []string, context Context) (Node, bool, error) {
+	result, err := compile(root, path, context)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if result == nil {
+		return root, false, nil
+	}
+
+	return result, true, nil
+}
+
+// rekey computes the key of a map entry from its (( ... )) expression. The
+// entry keeps its source key until the expression can be resolved.
+func (s *Spice) rekey(key string, val Node, path []string, context Context) (string, Node, bool, bool, error) {
+	keyed, ok := val.(*KeyedNode)
+	if !ok {
+		compiled, err := compile(key, path, context)
+		if err != nil {
+			return key, val, false, false, err
+		}
+
+		posh, ok := compiled.(*PoshNode)
+		if !ok {
+			return key, val, true, false, nil
+		}
+
+		keyed = &KeyedNode{Node: val, Key: posh.Expression, source: posh.source}
+	}
+
+	evaluated, err := keyed.Key.Evaluate(context, s.Stubs)
+	if err != nil && !missing(err) {
+		return key, val, false, false, &EvaluationError{Path: childPath(path, key), Source: keyed.source, Err: err}
+	}
+
+	if unwrap(evaluated) == nil {
+		return key, keyed, false, keyed != val, nil
+	}
+
+	computed, ok := scalarString(unwrap(evaluated))
+	if !ok {
+		return key, val, false, false, &EvaluationError{
+			Path:   childPath(path, key),
+			Source: keyed.source,
+			Err:    errors.New("a key must be a string or int, not " + typeName(unwrap(evaluated))),
+		}
+	}
+
+	node := keyed.Node
+	if len(keyed.Bindings) > 0 {
+		node = bind(node, keyed.Bindings, childPath(path, computed), context)
+	}
+
+	return computed, node, true, true, nil
+}
+
+// decide evaluates the condition of a conditional node, returning its body
+// and whether to include it. Undecided nodes stay in place.
+func (s *Spice) decide(root Node, path []string, context Context) (Node, bool, bool, error) {
+	cond, ok := root.(*ConditionalNode)
+	if !ok {
+		return root, true, false, nil
 	}
 
-	return Node(newList), didFlow
+	val, err := cond.Condition.Evaluate(context, s.Stubs)
+	if err != nil && !missing(err) {
+		return nil, false, false, &EvaluationError{Path: path, Source: cond.source, Err: err}
+	}
+
+	val = unwrap(val)
+	if val == nil {
+		return cond, true, false, nil
+	}
+
+	include, ok := val.(bool)
+	if !ok {
+		return nil, false, false, &EvaluationError{
+			Path:   path,
+			Source: cond.source,
+			Err:    errors.New(fmt.Sprintf("condition must be a boolean, not %s", typeName(val))),
+		}
+	}
+
+	if !include {
+		return nil, false, true, nil
+	}
+
+	return cond.Node, true, true, nil
+}
+
+// expand instantiates a loop's body once its list can be resolved,
+// returning the instances along with their identities, which follow the
+// loop's. The instances are nil until then.
+func (s *Spice) expand(loop *LoopNode, path []string, id string, context Context) ([]Node, []string, error) {
+	val, err := loop.List.Evaluate(context, s.Stubs)
+	if err != nil && !missing(err) {
+		return nil, nil, &EvaluationError{Path: path, Source: loop.source, Err: err}
+	}
+
+	if unwrap(val) == nil {
+		return nil, nil, nil
+	}
+
+	list, ok := listFrom(unwrap(val))
+	if !ok {
+		return nil, nil, &EvaluationError{
+			Path:   path,
+			Source: loop.source,
+			Err:    errors.New("only a list can be looped over, not " + typeName(unwrap(val))),
+		}
+	}
+
+	ids := instances(id, len(list))
+
+	expanded := []Node{}
+
+	for i, val := range list {
+		bindings := append(append([]Binding{}, loop.Bindings...), Binding{
+			Depth:  len(context),
+			Values: map[string]Node{loop.Variable: val},
+		})
+
+		body := loop.Node
+
+		// resolve the name up front so the instance gets its own path
+		attrs, ok := body.(map[string]Node)
+		if ok {
+			name, ok, err := s.boundName(attrs, bindings, path, context)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if ok {
+				named := make(map[string]Node)
+				for key, val := range attrs {
+					named[key] = val
+				}
+
+				named["name"] = name
+				body = named
+			}
+		}
+
+		expanded = append(expanded, bind(body, bindings, identifiedPath(path, ids[i], body), context))
+	}
+
+	return expanded, ids, nil
+}
+
+func (s *Spice) boundName(attrs map[string]Node, bindings []Binding, path []string, context Context) (string, bool, error) {
+	source, ok := attrs["name"].(string)
+	if !ok {
+		return "", false, nil
+	}
+
+	posh, ok := compileEmbedded(source, path, context).(*PoshNode)
+	if !ok {
+		return source, true, nil
+	}
+
+	bound := &BoundExpr{Bindings: bindings, Expression: posh.Expression}
+
+	val, err := bound.Evaluate(context, s.Stubs)
+	if err != nil && !missing(err) {
+		return "", false, &EvaluationError{Path: childPath(path, "name"), Source: posh.source, Err: err}
+	}
+
+	name, ok := stringFrom(val)
+
+	return name, ok, nil
+}
+
+// bind compiles the expressions in a loop body, binding the loop variables
+// in each of them
+func bind(root Node, bindings []Binding, path []string, context Context) Node {
+	switch root.(type) {
+	case map[string]Node:
+		dir, ok := directive(root.(map[string]Node), path, context)
+		if ok {
+			switch dir.(type) {
+			case *ConditionalNode:
+				cond := dir.(*ConditionalNode)
+
+				return &ConditionalNode{
+					Node:      bind(cond.Node, bindings, path, context),
+					Condition: &BoundExpr{Bindings: bindings, Expression: cond.Condition},
+					source:    cond.source,
+				}
+
+			case *LoopNode:
+				loop := dir.(*LoopNode)
+
+				return &LoopNode{
+					Node:     loop.Node,
+					Variable: loop.Variable,
+					List:     &BoundExpr{Bindings: bindings, Expression: loop.List},
+					Bindings: bindings,
+					source:   loop.source,
+				}
+			}
+		}
+
+		bound := make(map[string]Node)
+
+		for key, val := range root.(map[string]Node) {
+			// computed keys are bound once they are known
+			posh, ok := compileEmbedded(key, path, context).(*PoshNode)
+			if ok {
+				bound[key] = &KeyedNode{
+					Node:     val,
+					Key:      &BoundExpr{Bindings: bindings, Expression: posh.Expression},
+					Bindings: bindings,
+					source:   posh.source,
+				}
+
+				continue
+			}
+
+			bound[key] = bind(val, bindings, childPath(path, key), context)
+		}
+
+		return Node(bound)
+
+	case []Node:
+		bound := []Node{}
+
+		for i, val := range root.([]Node) {
+			bound = append(bound, bind(val, bindings, entryPath(path, i, val), context))
+		}
+
+		return Node(bound)
+
+	case string:
+		posh, ok := compileEmbedded(root.(string), path, context).(*PoshNode)
+		if !ok {
+			return root
+		}
+
+		posh.Expression = &BoundExpr{Bindings: bindings, Expression: posh.Expression}
+
+		return posh
+
+	case *PoshNode:
+		// an import, already scoped to the file it is in
+		posh := root.(*PoshNode)
+
+		return &PoshNode{
+			Expression: &BoundExpr{Bindings: bindings, Expression: posh.Expression},
+			source:     posh.source,
+			path:       path,
+			context:    context,
+		}
+
+	default:
+		return root
+	}
 }
 
-func (s *Spice) flowScalar(root string, path []string, context Context) (Node, bool) {
-	sub := embeddedPosh.FindStringSubmatch(root)
+// listMerge finds an (( merge )) entry in a list, returning it along with
+// the rest of the entries
+func listMerge(root []Node, path []string, context Context) (*MergeExpr, []Node) {
+	var merge *MergeExpr
+
+	entries := []Node{}
+
+	for _, val := range root {
+		expr, ok := listMergeEntry(val, path, context)
+		if ok {
+			merge = expr
+			continue
+		}
+
+		entries = append(entries, val)
+	}
+
+	return merge, entries
+}
+
+func listMergeEntry(entry Node, path []string, context Context) (*MergeExpr, bool) {
+	source, ok := entry.(string)
+	if !ok {
+		return nil, false
+	}
+
+	posh, ok := compileEmbedded(source, path, context).(*PoshNode)
+	if !ok {
+		return nil, false
+	}
+
+	merge, ok := posh.Expression.(*MergeExpr)
+	if !ok || merge.Deep {
+		return nil, false
+	}
+
+	return merge, true
+}
+
+// settled checks that every entry of a list has been expanded and has a
+// known key, so that stub entries can be matched against them
+func settled(entries []Node, key string, path []string, context Context) bool {
+	for _, entry := range entries {
+		switch entry.(type) {
+		case *LoopNode, *ConditionalNode:
+			return false
+
+		case map[string]Node:
+			attrs := entry.(map[string]Node)
+
+			_, isDirective := directive(attrs, path, context)
+			if isDirective {
+				return false
+			}
+
+			if uncompiled(attrs[key]) {
+				return false
+			}
+
+			_, ok := attrs[key].(*PoshNode)
+			if ok {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// overlay deep-merges a resolved << entry into the rest of its map, with the
+// entry's values taking precedence
+func overlay(root map[string]Node) (Node, bool) {
+	overrides, ok := mapFrom(root["<<"])
+	if !ok {
+		return nil, false
+	}
+
+	base := make(map[string]Node)
+
+	for key, val := range root {
+		if key != "<<" {
+			base[key] = val
+		}
+	}
+
+	return Node(mergeMaps(base, overrides)), true
+}
+
+// directive recognizes a map with a single (( if ... )) or (( for ... )) key
+func directive(root map[string]Node, path []string, context Context) (Node, bool) {
+	if len(root) != 1 {
+		return nil, false
+	}
+
+	for key, body := range root {
+		posh, ok := compileEmbedded(key, path, context).(*PoshNode)
+		if !ok {
+			return nil, false
+		}
+
+		switch posh.Expression.(type) {
+		case *IfExpr:
+			return &ConditionalNode{
+				Node:      body,
+				Condition: posh.Expression.(*IfExpr).Condition,
+				source:    posh.source,
+			}, true
+
+		case *ForExpr:
+			loop := posh.Expression.(*ForExpr)
+
+			return &LoopNode{
+				Node:     body,
+				Variable: loop.Variable,
+				List:     loop.List,
+				source:   posh.source,
+			}, true
+		}
+	}
+
+	return nil, false
+}
+
+// environment is the outermost scope, holding the allowed environment
+// variables under a name that no reference can spell
+func (s *Spice) environment() map[string]Node {
+	env := map[string]Node{}
+
+	for _, name := range s.Env {
+		val, found := os.LookupEnv(name)
+		if found {
+			env[name] = Node(val)
+		}
+	}
+
+	return map[string]Node{
+		environmentScope:  Node(env),
+		importDirectory:   Node(s.Dir),
+		settledLevel:      Node(s.settled),
+		importedPositions: Node(s.Positions),
+		importedLayout:    Node(s.Layout),
+	}
+}
+
+// extract removes a top-level declaration from a template, returning its
+// value along with the rest of the template
+func extract(root Node, key string) (Node, Node) {
+	attrs, ok := root.(map[string]Node)
+	if !ok {
+		return nil, root
+	}
+
+	val, found := attrs[key]
+	if !found {
+		return nil, root
+	}
+
+	rest := make(map[string]Node)
+
+	for name, val := range attrs {
+		if name != key {
+			rest[name] = val
+		}
+	}
+
+	return val, Node(rest)
+}
+
+// childPath copies the path so that siblings never share a backing array
+func childPath(path []string, step string) []string {
+	child := make([]string, len(path), len(path)+1)
+	copy(child, path)
+	return append(child, step)
+}
+
+// entryPath addresses list entries by their name, if they have one, and
+// by their index otherwise
+func entryPath(path []string, index int, entry Node) []string {
+	return identifiedPath(path, strconv.Itoa(index), entry)
+}
+
+// identifiedPath addresses list entries by their name, if they have one,
+// and by their identity otherwise
+func identifiedPath(path []string, id string, entry Node) []string {
+	attrs, ok := entry.(map[string]Node)
+	if ok {
+		name, ok := attrs["name"].(string)
+		if ok && pathName.MatchString(name) {
+			return childPath(path, name)
+		}
+	}
+
+	return childPath(path, id)
+}
+
+// compileEmbedded compiles the (( ... )) expression in a string, if it has
+// one that parses. Its errors are reported when the string is flowed.
+func compileEmbedded(source string, path []string, context Context) Node {
+	compiled, err := compile(source, path, context)
+	if err != nil {
+		return nil
+	}
+
+	return compiled
+}
+
+func compile(source string, path []string, context Context) (Node, error) {
+	sub := embeddedPosh.FindStringSubmatchIndex(source)
 	if sub == nil {
-		return root, false
+		return nil, nil
 	}
 
-	poshContent := sub[1]
+	poshContent := source[sub[2]:sub[3]]
 
 	posh := &Posh{Buffer: poshContent}
 	posh.Init()
 
 	if err := posh.Parse(); err != nil {
-		log.Fatal(err)
+		parseErr := newParseError(posh, path)
+		parseErr.offset = sub[2]
+		return nil, parseErr
 	}
 
-	result := compileTokens(posh, path, context)
-	if result == nil {
-		return root, false
+	compiled, err := compileTokens(posh, path, context)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("%s: could not compile (( %s )): %s", strings.Join(path, "."), poshContent, err))
 	}
 
-	return result, true
+	return compiled, nil
 }
@@ -0,0 +1,20 @@
+package posh
+
+import (
+	"testing"
+)
+
+func TestConditionMustBeBoolean(t *testing.T) {
+	template := `
+prod: "yes"
+suffix:
+  (( if prod )): "-prod"
+`
+
+	_, err := (&Spice{}).Evaluate(parse(t, template))
+
+	_, ok := err.(*EvaluationError)
+	if !ok {
+		t.Fatalf("expected an evaluation error, got %#v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package posh
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Unresolved is a node of a template that could not be evaluated.
+type Unresolved struct {
+	Path     string
+	Source   string
+	Reason   string
+	Position Position
+}
+
+// UnresolvedError lists every node of a template that could not be
+// evaluated.
+type UnresolvedError struct {
+	Nodes []Unresolved
+}
+
+func (e *UnresolvedError) Error() string {
+	msg := fmt.Sprintf("could not resolve %d expressions:\n", len(e.Nodes))
+
+	for _, node := range e.Nodes {
+		msg += "  "
+
+		if node.Position.File != "" {
+			msg += node.Position.String() + ": "
+		}
+
+		msg += fmt.Sprintf("%s: (( %s )): %s\n", node.Path, node.Source, node.Reason)
+	}
+
+	return msg
+}
+
+// EvaluationError is an expression that can never evaluate, such as adding
+// a string to an int.
+type EvaluationError struct {
+	Path     []string
+	Source   string
+	Err      error
+	Position Position
+}
+
+func (e *EvaluationError) Error() string {
+	msg := ""
+
+	if e.Position.File != "" {
+		msg += e.Position.String() + ": "
+	}
+
+	return msg + fmt.Sprintf("%s: (( %s )): %s", strings.Join(e.Path, "."), e.Source, e.Err)
+}
+
+// CheckResolved returns an *UnresolvedError listing every node of a flowed
+// template that could not be evaluated, along with why. Unnamed list entries
+// are listed by their identity, as they are while flowing.
+func CheckResolved(root Node, stubs []Node, entries Entries) error {
+	unresolved := []Unresolved{}
+
+	// the empty scope stands in for the environment, so that loop bindings
+	// sit at the same depths as they did while flowing
+	collectUnresolved(root, []string{}, Context{{}}, stubs, entries, &unresolved)
+
+	if len(unresolved) == 0 {
+		return nil
+	}
+
+	sort.Sort(byUnresolvedPath(unresolved))
+
+	return &UnresolvedError{Nodes: unresolved}
+}
+
+func collectUnresolved(root Node, path []string, context Context, stubs []Node, entries Entries, unresolved *[]Unresolved) {
+	add := func(path []string, source string, reason string) {
+		*unresolved = append(*unresolved, Unresolved{
+			Path:   strings.Join(path, "."),
+			Source: source,
+			Reason: reason,
+		})
+	}
+
+	switch root.(type) {
+	case map[string]Node:
+		attrs := root.(map[string]Node)
+
+		context = append(context, attrs)
+
+		for key, val := range attrs {
+			keyed, ok := val.(*KeyedNode)
+			if ok {
+				add(childPath(path, key), keyed.source, "key "+reason(keyed.Key, context, stubs))
+				continue
+			}
+
+			collectUnresolved(val, childPath(path, key), context, stubs, entries, unresolved)
+		}
+
+	case []Node:
+		list := root.([]Node)
+
+		for i, val := range list {
+			if uncompiled(val) {
+				source := embeddedPosh.FindStringSubmatch(val.(string))[1]
+				add(path, source, "the names of the other entries never resolved")
+				continue
+			}
+
+			collectUnresolved(val, entries.path(path, i, val, len(list)), context, stubs, entries, unresolved)
+		}
+
+	case *PoshNode:
+		posh := root.(*PoshNode)
+
+		add(path, posh.source, reason(posh.Expression, context, stubs))
+
+	case *ConditionalNode:
+		cond := root.(*ConditionalNode)
+
+		add(path, cond.source, "condition "+reason(cond.Condition, context, stubs))
+
+	case *LoopNode:
+		loop := root.(*LoopNode)
+
+		add(path, loop.source, "list "+reason(loop.List, context, stubs))
+
+	case string, int, bool, TextNode, *DeletedNode:
+
+	default:
+		add(path, "", fmt.Sprintf("unknown node type %T", root))
+	}
+}
+
+// reason explains why an expression did not evaluate: a missing reference
+// or stub key, or an operand that never resolved
+func reason(expr Expression, context Context, stubs []Node) string {
+	switch expr.(type) {
+	case *ReferenceExpr:
+		ref := expr.(*ReferenceExpr)
+		name := strings.Join(ref.Path, ".")
+
+		root, found := resolveSymbol(ref.Path[0], context)
+		if !found {
+			return name + " is not defined"
+		}
+
+		here := root
+
+		for i, step := range ref.Path {
+			if i > 0 {
+				var found bool
+
+				here, found = nextStep(step, here)
+				if !found {
+					return name + " is not defined"
+				}
+			}
+
+			if uncompiled(here) || unwrap(here) == nil {
+				return strings.Join(ref.Path[:i+1], ".") + " is unresolved"
+			}
+		}
+
+		return name + " is unresolved"
+
+	case *MergeExpr:
+		merge := expr.(*MergeExpr)
+
+		if len(stubs) == 0 {
+			return "no stubs were given to merge " + strings.Join(merge.Path, ".")
+		}
+
+		return "no stub defines " + strings.Join(merge.Path, ".")
+
+	case *AutoExpr:
+		return "could not compute " + strings.Join(expr.(*AutoExpr).Path, ".") + " from the jobs"
+
+	case *OrExpr:
+		e := expr.(*OrExpr)
+		return reasons(context, stubs, e.A, e.B)
+
+	case *ConcatenationExpr:
+		e := expr.(*ConcatenationExpr)
+		return reasons(context, stubs, e.A, e.B)
+
+	case *AdditionExpr:
+		e := expr.(*AdditionExpr)
+		return reasons(context, stubs, e.A, e.B)
+
+	case *SubtractionExpr:
+		e := expr.(*SubtractionExpr)
+		return reasons(context, stubs, e.A, e.B)
+
+	case *CallExpr:
+		call := expr.(*CallExpr)
+
+		if call.Name == "env" && len(call.Arguments) == 1 {
+			name, _ := call.Arguments[0].Evaluate(context, stubs)
+			if name != nil {
+				return "the environment variable is unset or not allowed with -env"
+			}
+		}
+
+		return reasons(context, stubs, call.Arguments...)
+
+	case *ListExpr:
+		return reasons(context, stubs, expr.(*ListExpr).Contents...)
+
+	case *SeqExpr:
+		return reasons(context, stubs, expr.(*SeqExpr).Expressions...)
+
+	case *SpreadExpr:
+		return reasons(context, stubs, expr.(*SpreadExpr).List)
+
+	case *RangeExpr:
+		e := expr.(*RangeExpr)
+		return reasons(context, stubs, e.From, e.To)
+
+	case *ComprehensionExpr:
+		e := expr.(*ComprehensionExpr)
+		return reasons(context, stubs, e.List)
+
+	case *IfExpr:
+		return reason(expr.(*IfExpr).Condition, context, stubs)
+
+	case *ForExpr:
+		return reasons(context, stubs, expr.(*ForExpr).List)
+
+	case *BoundExpr:
+		e := expr.(*BoundExpr)
+		return reason(e.Expression, e.scope(context), stubs)
+	}
+
+	return "could not be evaluated"
+}
+
+// reasons explains whichever of the expressions did not evaluate
+func reasons(context Context, stubs []Node, exprs ...Expression) string {
+	whys := []string{}
+
+	for _, expr := range exprs {
+		val, _ := expr.Evaluate(context, stubs)
+		if unwrap(val) == nil {
+			whys = append(whys, reason(expr, context, stubs))
+		}
+	}
+
+	if len(whys) == 0 {
+		return "could not be evaluated"
+	}
+
+	return strings.Join(whys, "; ")
+}
+
+func typeName(node Node) string {
+	switch node.(type) {
+	case map[string]Node:
+		return "map"
+	case []Node:
+		return "list"
+	case string, TextNode:
+		return "string"
+	case int:
+		return "int"
+	case bool:
+		return "bool"
+	default:
+		return fmt.Sprintf("%T", node)
+	}
+}
+
+type byUnresolvedPath []Unresolved
+
+func (nodes byUnresolvedPath) Len() int           { return len(nodes) }
+func (nodes byUnresolvedPath) Swap(i, j int)      { nodes[i], nodes[j] = nodes[j], nodes[i] }
+func (nodes byUnresolvedPath) Less(i, j int) bool { return nodes[i].Path < nodes[j].Path }
@@ -0,0 +1,110 @@
+package posh
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnresolvedUnnamedEntries(t *testing.T) {
+	template := `
+things:
+- b: (( missing ))
+- b: (( missing ))
+- name: named
+  b: (( missing ))
+`
+
+	evaluated := evaluate(t, template)
+
+	err := CheckResolved(evaluated, nil, nil)
+
+	unresolvedErr, ok := err.(*UnresolvedError)
+	if !ok {
+		t.Fatalf("expected unresolved expressions, got %#v", err)
+	}
+
+	paths := []string{}
+	for _, node := range unresolvedErr.Nodes {
+		paths = append(paths, node.Path)
+	}
+
+	expected := []string{"things.0.b", "things.1.b", "things.named.b"}
+
+	if len(paths) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+
+	for i, path := range expected {
+		if paths[i] != path {
+			t.Errorf("expected %v, got %v", expected, paths)
+		}
+	}
+}
+
+func TestUnresolvedPathsAfterConditionals(t *testing.T) {
+	template := `
+flag: false
+things:
+- (( if flag )):
+    b: 1
+- b: (( missing ))
+  c: 2
+`
+
+	spice := &Spice{}
+
+	evaluated, err := spice.Evaluate(parse(t, template))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = CheckResolved(evaluated, nil, spice.Entries)
+
+	unresolvedErr, ok := err.(*UnresolvedError)
+	if !ok {
+		t.Fatalf("expected unresolved expressions, got %#v", err)
+	}
+
+	if len(unresolvedErr.Nodes) != 1 || unresolvedErr.Nodes[0].Path != "things.1.b" {
+		t.Fatalf("expected things.1.b to be unresolved, got %v", unresolvedErr.Nodes)
+	}
+
+	path := strings.Split(unresolvedErr.Nodes[0].Path, ".")
+
+	_, err = spice.Explain(evaluated, path)
+	if err != nil {
+		t.Errorf("expected the reported path to be explained, got %s", err)
+	}
+
+	scopes, err := Scopes(evaluated, path, spice.Entries)
+	if err != nil {
+		t.Fatalf("expected the reported path to have scopes, got %s", err)
+	}
+
+	if scopes[0].String() != "things.1" || scopes[0].Map["c"] != 2 {
+		t.Errorf("expected the entry to be the nearest scope, got %v", scopes)
+	}
+}
+
+func TestEvaluationErrorPathsAfterConditionals(t *testing.T) {
+	template := `
+flag: false
+things:
+- (( if flag )):
+    b: 1
+- b: (( 1 + "x" ))
+`
+
+	spice := &Spice{}
+
+	_, err := spice.Evaluate(parse(t, template))
+
+	evalErr, ok := err.(*EvaluationError)
+	if !ok {
+		t.Fatalf("expected an evaluation error, got %#v", err)
+	}
+
+	if strings.Join(evalErr.Path, ".") != "things.1.b" {
+		t.Errorf("expected things.1.b to fail, got %v", evalErr.Path)
+	}
+}